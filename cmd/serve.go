@@ -62,6 +62,14 @@ var serveCmd = &cobra.Command{
 			handler.RoomMgr.SetBroker(broker)
 		}
 
+		// Background workers need MongoDB; they are stopped during shutdown.
+		if db.DB != nil {
+			handler.StartRetentionPurger()
+			handler.StartMessageExpirer()
+			handler.StartScheduler()
+			handler.StartPollCloser()
+		}
+
 		e := echo.New()
 
 		router.Router(e)
@@ -102,6 +110,13 @@ var serveCmd = &cobra.Command{
 			e.Logger.Fatal(err)
 		}
 
+		// Let running background passes finish before the insert queue and
+		// the database go away.
+		handler.StopRetentionPurger()
+		handler.StopMessageExpirer()
+		handler.StopScheduler()
+		handler.StopPollCloser()
+
 		// Drain and close the insert queue so no chat messages are lost (#98).
 		handler.ShutdownInsertQueue()
 
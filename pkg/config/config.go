@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/jinzhu/configor"
@@ -25,6 +26,35 @@ var ShutdownTimeout = 30 * time.Second
 // Controlled by the HUB_IDLE_TIMEOUT env var (e.g. "5m", "10m"). Default: 5m.
 var HubIdleTimeout = 5 * time.Minute
 
+// ReadReceiptMaxMembers is the largest room (by member count) whose members
+// receive READ receipts from each other. 0 disables read receipts.
+// Controlled by the READ_RECEIPT_MAX_MEMBERS env var. Default: 20.
+var ReadReceiptMaxMembers = 20
+
+// ClientMsgIDWindow is how long a client_msg_id is remembered per user and room
+// to drop resent duplicates.
+// Controlled by the CLIENT_MSG_ID_WINDOW env var (e.g. "5m", "1h"). Default: 5m.
+var ClientMsgIDWindow = 5 * time.Minute
+
+// DMMaxMembers is the largest number of participants in a group DM,
+// including its creator.
+// Controlled by the DM_MAX_MEMBERS env var. Default: 8.
+var DMMaxMembers = 8
+
+// RetentionDays is the server-wide message retention in days; rooms may set
+// their own. 0 keeps messages forever.
+// Controlled by the RETENTION_DAYS env var. Default: 0.
+var RetentionDays = 0
+
+// RetentionMaxMessages is the server-wide number of newest messages a room
+// keeps; rooms may set their own. 0 keeps every message.
+// Controlled by the RETENTION_MAX_MESSAGES env var. Default: 0.
+var RetentionMaxMessages = 0
+
+// RetentionPurgeInterval is how often expired messages and files are purged.
+// Controlled by the RETENTION_PURGE_INTERVAL env var (e.g. "30m", "6h"). Default: 1h.
+var RetentionPurgeInterval = time.Hour
+
 // mongoDB config
 type dbConfig struct {
 	URI      string `env:"MONGODB_URI" validate:"required"`
@@ -110,6 +140,42 @@ func LoadAll() error {
 		}
 	}
 
+	if v := os.Getenv("CLIENT_MSG_ID_WINDOW"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			ClientMsgIDWindow = d
+		}
+	}
+
+	if v := os.Getenv("READ_RECEIPT_MAX_MEMBERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			ReadReceiptMaxMembers = n
+		}
+	}
+
+	if v := os.Getenv("DM_MAX_MEMBERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 2 {
+			DMMaxMembers = n
+		}
+	}
+
+	if v := os.Getenv("RETENTION_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			RetentionDays = n
+		}
+	}
+
+	if v := os.Getenv("RETENTION_MAX_MESSAGES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			RetentionMaxMessages = n
+		}
+	}
+
+	if v := os.Getenv("RETENTION_PURGE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			RetentionPurgeInterval = d
+		}
+	}
+
 	return nil
 }
 
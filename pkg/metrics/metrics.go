@@ -30,6 +30,13 @@ var (
 		Help: "Total number of chat messages dropped because insertQueue was full.",
 	})
 
+	// MessagesDeadLettered counts chat messages parked in the dead-letter collection
+	// after all insert retries failed.
+	MessagesDeadLettered = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "woongkie_messages_dead_lettered_total",
+		Help: "Total number of chat messages moved to the dead-letter collection after failed inserts.",
+	})
+
 	// RedisMessagesDropped counts Redis Pub/Sub messages dropped due to a slow consumer.
 	RedisMessagesDropped = promauto.NewCounter(prometheus.CounterOpts{
 		Name: "woongkie_redis_messages_dropped_total",
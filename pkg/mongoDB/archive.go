@@ -0,0 +1,53 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SetRoomArchived archives or unarchives roomID and returns the updated room.
+// Archived rooms keep their history but accept no new messages or members.
+// Returns ErrNotFound when the room does not exist or is already in that state.
+func SetRoomArchived(roomID string, archived bool, by string) (*Room, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	filter := bson.D{{Key: "_id", Value: objID}}
+	var update bson.D
+	if archived {
+		filter = append(filter, bson.E{Key: "archived", Value: bson.D{{Key: "$ne", Value: true}}})
+		update = bson.D{{Key: "$set", Value: bson.D{
+			{Key: "archived", Value: true},
+			{Key: "archived_at", Value: time.Now()},
+			{Key: "archived_by", Value: by},
+		}}}
+	} else {
+		filter = append(filter, bson.E{Key: "archived", Value: true})
+		update = bson.D{{Key: "$unset", Value: bson.D{
+			{Key: "archived", Value: ""},
+			{Key: "archived_at", Value: ""},
+			{Key: "archived_by", Value: ""},
+		}}}
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var room Room
+	err = roomCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&room)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
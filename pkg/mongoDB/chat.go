@@ -3,6 +3,8 @@ package mongodb
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -27,32 +29,65 @@ func parseEncryptedKeys(s string) map[string]string {
 
 // ChatMessage is used for WebSocket message exchange (keep json tags as-is for compatibility)
 type ChatMessage struct {
-	Event         string `json:"Event,omitempty"`
-	User          string `json:"User"`
-	Message       string `json:"message"`
-	Owner         bool   `json:"owner,omitempty"`
-	RoomID        string `json:"room_id,omitempty"`
-	MessageID     string `json:"message_id,omitempty"`
-	CreatedAt     string `json:"created_at,omitempty"`
-	ReplyTo       string `json:"reply_to,omitempty"`
-	Encrypted     bool   `json:"encrypted,omitempty"`
-	EncryptedKeys string `json:"encrypted_keys,omitempty"`
+	Event         string              `json:"Event,omitempty"`
+	User          string              `json:"User"`
+	Message       string              `json:"message"`
+	Owner         bool                `json:"owner,omitempty"`
+	RoomID        string              `json:"room_id,omitempty"`
+	MessageID     string              `json:"message_id,omitempty"`
+	CreatedAt     string              `json:"created_at,omitempty"`
+	ReplyTo       string              `json:"reply_to,omitempty"`
+	Encrypted     bool                `json:"encrypted,omitempty"`
+	EncryptedKeys string              `json:"encrypted_keys,omitempty"`
+	Emoji         string              `json:"emoji,omitempty"`
+	Reactions     map[string][]string `json:"reactions,omitempty"` // emoji -> usernames
+	ReplyCount    int                 `json:"reply_count,omitempty"`
+	LastReplyAt   string              `json:"last_reply_at,omitempty"`
+	Seq           int64               `json:"seq,omitempty"`
+	ClientMsgID   string              `json:"client_msg_id,omitempty"`
+	Revision      int                 `json:"revision,omitempty"`
+	PinnedBy      string              `json:"pinned_by,omitempty"`
+	PinnedAt      string              `json:"pinned_at,omitempty"`
+	Mentions      *MessageMentions    `json:"mentions,omitempty"`
+	Role          string              `json:"role,omitempty"`       // ROLE_UPDATE: the user's new room role
+	Payload       json.RawMessage     `json:"payload,omitempty"`    // event-specific data, e.g. the room for ROOM_UPDATE
+	ExpiresIn     int64               `json:"expires_in,omitempty"` // MSG from clients: self-destruct after this many seconds
+	ExpiresAt     string              `json:"expires_at,omitempty"` // when an ephemeral message is removed
+	Poll          *Poll               `json:"poll,omitempty"`       // poll messages and POLL_UPDATE
 }
 
 // Chat is the MongoDB storage structure (flat, snake_case)
 type Chat struct {
-	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	CreatedAt     time.Time          `json:"created_at" bson:"created_at,omitempty"`
-	EditedAt      *time.Time         `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
-	RoomID        string             `json:"room_id" bson:"room_id,omitempty"`
-	Event         string             `json:"event,omitempty" bson:"event,omitempty"`
-	User          string             `json:"user" bson:"user"`
-	Message       string             `json:"message" bson:"message"`
-	Owner         bool               `json:"owner,omitempty" bson:"owner,omitempty"`
-	ReplyTo       string             `json:"reply_to,omitempty" bson:"reply_to,omitempty"`
-	IsDeleted     bool               `json:"is_deleted,omitempty" bson:"is_deleted,omitempty"`
-	Encrypted     bool               `json:"encrypted,omitempty" bson:"encrypted,omitempty"`
-	EncryptedKeys map[string]string  `json:"encrypted_keys,omitempty" bson:"encrypted_keys,omitempty"`
+	ID            primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	CreatedAt     time.Time           `json:"created_at" bson:"created_at,omitempty"`
+	EditedAt      *time.Time          `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
+	RoomID        string              `json:"room_id" bson:"room_id,omitempty"`
+	Event         string              `json:"event,omitempty" bson:"event,omitempty"`
+	User          string              `json:"user" bson:"user"`
+	Message       string              `json:"message" bson:"message"`
+	Owner         bool                `json:"owner,omitempty" bson:"owner,omitempty"`
+	ReplyTo       string              `json:"reply_to,omitempty" bson:"reply_to,omitempty"`
+	IsDeleted     bool                `json:"is_deleted,omitempty" bson:"is_deleted,omitempty"`
+	Encrypted     bool                `json:"encrypted,omitempty" bson:"encrypted,omitempty"`
+	EncryptedKeys map[string]string   `json:"encrypted_keys,omitempty" bson:"encrypted_keys,omitempty"`
+	Reactions     map[string][]string `json:"reactions,omitempty" bson:"reactions,omitempty"`
+	ReplyCount    int                 `json:"reply_count,omitempty" bson:"reply_count,omitempty"`
+	LastReplyAt   *time.Time          `json:"last_reply_at,omitempty" bson:"last_reply_at,omitempty"`
+	Seq           int64               `json:"seq,omitempty" bson:"seq,omitempty"`
+	ClientMsgID   string              `json:"client_msg_id,omitempty" bson:"client_msg_id,omitempty"`
+	Revision      int                 `json:"revision,omitempty" bson:"revision,omitempty"`
+	Revisions     []ChatRevision      `json:"-" bson:"revisions,omitempty"` // served only via the revisions API
+	Mentions      *MessageMentions    `json:"mentions,omitempty" bson:"mentions,omitempty"`
+	ExpiresAt     *time.Time          `json:"expires_at,omitempty" bson:"expires_at,omitempty"` // ephemeral messages only
+	Poll          *Poll               `json:"poll,omitempty" bson:"poll,omitempty"`
+}
+
+// ChatRevision is one version of a message's text. Revision 0 is the original.
+type ChatRevision struct {
+	Revision int       `json:"revision" bson:"revision"`
+	Message  string    `json:"message" bson:"message"`
+	EditedBy string    `json:"edited_by" bson:"edited_by"`
+	EditedAt time.Time `json:"edited_at" bson:"edited_at"`
 }
 
 var chatCollection *mongo.Collection
@@ -78,6 +113,63 @@ func InitChatCollection(database *mongo.Database) error {
 		return err
 	}
 
+	// Thread index: replies to a parent message ordered by time
+	threadIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "reply_to", Value: 1},
+			{Key: "created_at", Value: -1},
+		},
+	}
+	if _, err := chatCollection.Indexes().CreateOne(ctx, threadIndex); err != nil {
+		return err
+	}
+
+	// Sequence index: per-room seq is unique; legacy documents without seq are exempt.
+	seqIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "room_id", Value: 1},
+			{Key: "seq", Value: 1},
+		},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.D{{Key: "seq", Value: bson.D{{Key: "$exists", Value: true}}}}),
+	}
+	if _, err := chatCollection.Indexes().CreateOne(ctx, seqIndex); err != nil {
+		return err
+	}
+
+	// Client message index: a resent client_msg_id is acked with the
+	// message stored for the first send.
+	clientMsgIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "room_id", Value: 1},
+			{Key: "user", Value: 1},
+			{Key: "client_msg_id", Value: 1},
+		},
+		Options: options.Index().
+			SetPartialFilterExpression(bson.D{{Key: "client_msg_id", Value: bson.D{{Key: "$exists", Value: true}}}}),
+	}
+	if _, err := chatCollection.Indexes().CreateOne(ctx, clientMsgIndex); err != nil {
+		return err
+	}
+
+	// Expiry index: the expirer deletes ephemeral messages, except in rooms
+	// under legal hold, and announces MSG_EXPIRE.
+	expiryIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "expires_at", Value: 1}},
+	}
+	if _, err := chatCollection.Indexes().CreateOne(ctx, expiryIndex); err != nil {
+		return err
+	}
+
+	// Poll index: the poll closer finds polls past their close time.
+	pollIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "poll.closes_at", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	}
+	if _, err := chatCollection.Indexes().CreateOne(ctx, pollIndex); err != nil {
+		return err
+	}
+
 	// Text index for full-text message search
 	textIndex := mongo.IndexModel{
 		Keys: bson.D{{Key: "message", Value: "text"}},
@@ -146,56 +238,122 @@ func migrateChatSchema(ctx context.Context) error {
 	return nil
 }
 
+// chatFromMessage builds the storage document for a wire message. The broadcast
+// MessageID and CreatedAt are kept when present so clients can act on the IDs
+// they received; otherwise the driver assigns an _id and the current time is used.
+func chatFromMessage(msg ChatMessage) Chat {
+	// #289: include ReplyTo and EncryptedKeys
+	chat := Chat{
+		CreatedAt:     time.Now(),
+		RoomID:        msg.RoomID,
+		Event:         msg.Event,
+		User:          msg.User,
+		Message:       msg.Message,
+		Owner:         msg.Owner,
+		ReplyTo:       msg.ReplyTo,
+		Encrypted:     msg.Encrypted,
+		EncryptedKeys: parseEncryptedKeys(msg.EncryptedKeys),
+		Seq:           msg.Seq,
+		ClientMsgID:   msg.ClientMsgID,
+		Mentions:      msg.Mentions,
+		Poll:          msg.Poll,
+	}
+	if oid, err := primitive.ObjectIDFromHex(msg.MessageID); err == nil {
+		chat.ID = oid
+	}
+	if t, err := time.Parse(time.RFC3339Nano, msg.CreatedAt); err == nil {
+		chat.CreatedAt = t
+	}
+	if t, err := time.Parse(time.RFC3339Nano, msg.ExpiresAt); err == nil {
+		chat.ExpiresAt = &t
+	}
+	return chat
+}
+
 // InsertManyChat bulk-inserts a slice of ChatMessages into MongoDB.
-// Returns the count of inserted documents and any error.
+// The insert is unordered and idempotent: documents whose _id already exists
+// (e.g. from an earlier, partially failed attempt) count as inserted, so a
+// failed batch can be retried as a whole.
+// Returns the count of newly inserted documents and any other error.
 func InsertManyChat(messages []ChatMessage) (int, error) {
 	if len(messages) == 0 {
 		return 0, nil
 	}
+	chats := make([]Chat, len(messages))
+	for i, msg := range messages {
+		chats[i] = chatFromMessage(msg)
+	}
+	return insertChatDocs(chats)
+}
+
+// insertChatDocs inserts chats unordered. A duplicate key only counts as
+// already inserted when the same message is stored under its _id; any other
+// collision, such as another message holding its room seq, is an error
+// wrapping ErrSeqConflict.
+func insertChatDocs(chats []Chat) (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// #289: include ReplyTo and EncryptedKeys in bulk insert
-	docs := make([]interface{}, len(messages))
-	for i, msg := range messages {
-		docs[i] = Chat{
-			CreatedAt:     time.Now(),
-			RoomID:        msg.RoomID,
-			Event:         msg.Event,
-			User:          msg.User,
-			Message:       msg.Message,
-			Owner:         msg.Owner,
-			ReplyTo:       msg.ReplyTo,
-			Encrypted:     msg.Encrypted,
-			EncryptedKeys: parseEncryptedKeys(msg.EncryptedKeys),
+	docs := make([]interface{}, len(chats))
+	for i, chat := range chats {
+		docs[i] = chat
+	}
+	result, err := chatCollection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	inserted := 0
+	if result != nil {
+		inserted = len(result.InsertedIDs)
+	}
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, we := range bulkErr.WriteErrors {
+			if !mongo.IsDuplicateKeyError(we) || we.Index < 0 || we.Index >= len(chats) {
+				return inserted, err
+			}
+			stored, findErr := alreadyStored(ctx, chats[we.Index])
+			if findErr != nil {
+				return inserted, findErr
+			}
+			if !stored {
+				return inserted, fmt.Errorf("message %s seq %d: %w", chats[we.Index].ID.Hex(), chats[we.Index].Seq, ErrSeqConflict)
+			}
 		}
+		return inserted, nil
 	}
+	return inserted, err
+}
 
-	result, err := chatCollection.InsertMany(ctx, docs)
+// alreadyStored reports whether chat is stored under its own _id, expired or
+// not, with the same room and seq.
+func alreadyStored(ctx context.Context, chat Chat) (bool, error) {
+	if chat.ID.IsZero() {
+		return false, nil
+	}
+	var stored Chat
+	err := chatCollection.FindOne(ctx, bson.D{{Key: "_id", Value: chat.ID}}).Decode(&stored)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
 	if err != nil {
-		return 0, err
+		return false, err
 	}
-	return len(result.InsertedIDs), nil
+	return stored.RoomID == chat.RoomID && stored.Seq == chat.Seq, nil
 }
 
 // chat message 저장 (room_id 포함)
+// A sequence number is allocated if chatMessage.Seq is unset.
 // Returns the inserted document ID as a hex string and any error.
 func InsertChat(chatMessage ChatMessage) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// #289: include ReplyTo and EncryptedKeys
-	chat := Chat{
-		CreatedAt:     time.Now(),
-		RoomID:        chatMessage.RoomID,
-		Event:         chatMessage.Event,
-		User:          chatMessage.User,
-		Message:       chatMessage.Message,
-		Owner:         chatMessage.Owner,
-		ReplyTo:       chatMessage.ReplyTo,
-		Encrypted:     chatMessage.Encrypted,
-		EncryptedKeys: parseEncryptedKeys(chatMessage.EncryptedKeys),
+	if chatMessage.Seq == 0 {
+		seq, err := NextRoomSeq(chatMessage.RoomID)
+		if err != nil {
+			return "", err
+		}
+		chatMessage.Seq = seq
 	}
+	chat := chatFromMessage(chatMessage)
 
 	result, err := chatCollection.InsertOne(ctx, chat)
 	if err != nil {
@@ -216,6 +374,7 @@ func FindChatByRoom(roomID string) (chat []Chat, err error) {
 
 	filter := bson.D{
 		{Key: "room_id", Value: roomID},
+		notExpired(),
 		{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
 	}
 	// #180: sort by created_at ASC with proper index usage
@@ -248,6 +407,7 @@ func FindChatByRoomBefore(roomID string, before time.Time, limit int64) (chat []
 
 	filter := bson.D{
 		{Key: "room_id", Value: roomID},
+		notExpired(),
 		{Key: "created_at", Value: bson.D{{Key: "$lt", Value: before}}},
 		{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
 	}
@@ -283,6 +443,7 @@ func FindChatByRoomAfter(roomID string, after time.Time) ([]Chat, error) {
 
 	filter := bson.D{
 		{Key: "room_id", Value: roomID},
+		notExpired(),
 		{Key: "created_at", Value: bson.D{{Key: "$gt", Value: after}}},
 		{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
 	}
@@ -308,6 +469,99 @@ func FindChatByRoomAfter(roomID string, after time.Time) ([]Chat, error) {
 	return chats, nil
 }
 
+// FindChatsByIDs returns the messages with the given ObjectID hex strings in
+// roomID, keyed by ID. Unknown or malformed IDs are skipped.
+func FindChatsByIDs(roomID string, messageIDs []string) (map[string]Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	oids := make([]primitive.ObjectID, 0, len(messageIDs))
+	for _, id := range messageIDs {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+	chats := make(map[string]Chat, len(oids))
+	if len(oids) == 0 {
+		return chats, nil
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: oids}}},
+		{Key: "room_id", Value: roomID},
+		notExpired(),
+	}
+	cur, err := chatCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var chat Chat
+		if err := cur.Decode(&chat); err != nil {
+			return nil, err
+		}
+		chats[chat.ID.Hex()] = chat
+	}
+	return chats, cur.Err()
+}
+
+// FindChatByRoomSinceSeq returns up to limit messages of roomID with seq greater
+// than since, in ascending seq order. Soft-deleted messages are included so that
+// callers can track replay progress; they must not be shown to users. Expired
+// ephemeral messages are never returned.
+func FindChatByRoomSinceSeq(roomID string, since, limit int64) ([]Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "room_id", Value: roomID},
+		notExpired(),
+		{Key: "seq", Value: bson.D{{Key: "$gt", Value: since}}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "seq", Value: 1}}).SetLimit(limit)
+
+	cur, err := chatCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	chats := []Chat{}
+	for cur.Next(ctx) {
+		var result Chat
+		if err := cur.Decode(&result); err != nil {
+			return nil, err
+		}
+		chats = append(chats, result)
+	}
+	return chats, cur.Err()
+}
+
+// OldestRoomSeq returns the lowest seq still stored for roomID, or 0 if the
+// room has no sequenced messages. Anything below it has been purged.
+func OldestRoomSeq(roomID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "room_id", Value: roomID},
+		{Key: "seq", Value: bson.D{{Key: "$exists", Value: true}}},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "seq", Value: 1}})
+
+	var oldest Chat
+	err := chatCollection.FindOne(ctx, filter, opts).Decode(&oldest)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return oldest.Seq, nil
+}
+
 // 기존 호환 - 모든 chat 가져오기
 func FindChat() (chat []Chat, err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -316,7 +570,7 @@ func FindChat() (chat []Chat, err error) {
 	chat = []Chat{}
 
 	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(500)
-	cur, err := chatCollection.Find(ctx, bson.D{}, opts)
+	cur, err := chatCollection.Find(ctx, bson.D{notExpired()}, opts)
 	if err != nil {
 		return chat, err
 	}
@@ -334,6 +588,23 @@ func FindChat() (chat []Chat, err error) {
 	return chat, nil
 }
 
+// ChatExists reports whether a message with the given ID is stored, even if
+// it has expired or been deleted.
+func ChatExists(messageID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return false, err
+	}
+	count, err := chatCollection.CountDocuments(ctx, bson.D{{Key: "_id", Value: objID}}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 // FindChatByID finds a single chat message by its ObjectID hex string.
 func FindChatByID(messageID string) (*Chat, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -345,18 +616,39 @@ func FindChatByID(messageID string) (*Chat, error) {
 	}
 
 	var chat Chat
-	err = chatCollection.FindOne(ctx, bson.D{{Key: "_id", Value: oid}}).Decode(&chat)
+	err = chatCollection.FindOne(ctx, bson.D{{Key: "_id", Value: oid}, notExpired()}).Decode(&chat)
 	if err != nil {
 		return nil, err
 	}
 	return &chat, nil
 }
 
+// FindChatByClientMsgID returns the latest message username sent to roomID
+// with clientMsgID.
+func FindChatByClientMsgID(roomID, username, clientMsgID string) (*Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "room_id", Value: roomID},
+		{Key: "user", Value: username},
+		{Key: "client_msg_id", Value: clientMsgID},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	var chat Chat
+	if err := chatCollection.FindOne(ctx, filter, opts).Decode(&chat); err != nil {
+		return nil, err
+	}
+	return &chat, nil
+}
+
 // EditChat updates the message text for the given message ID, only if the requesting
 // user is the owner, the message is within the 5-minute edit window, and not deleted.
 // The ownership, time-window, and deletion checks are performed atomically inside the
 // MongoDB filter to eliminate the TOCTOU race between FindOne and FindOneAndUpdate.
 // #202: roomID is included in the filter to ensure the message belongs to the correct room.
+// Every edit appends a ChatRevision and bumps Revision; the update only applies if
+// no other edit landed since the document was read, otherwise ErrEditConflict.
 func EditChat(messageID, username, roomID, newMessage string) (*Chat, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -394,16 +686,36 @@ func EditChat(messageID, username, roomID, newMessage string) (*Chat, error) {
 		{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
 		{Key: "created_at", Value: bson.D{{Key: "$gt", Value: cutoff}}},
 	}
+	if chat.Revision == 0 {
+		filter = append(filter, bson.E{Key: "revision", Value: bson.D{{Key: "$exists", Value: false}}})
+	} else {
+		filter = append(filter, bson.E{Key: "revision", Value: chat.Revision})
+	}
+
 	now := time.Now()
-	update := bson.D{{Key: "$set", Value: bson.D{
-		{Key: "message", Value: newMessage},
-		{Key: "edited_at", Value: now},
-	}}}
+	revisions := bson.A{}
+	if len(chat.Revisions) == 0 {
+		// First edit: keep the original text as revision 0.
+		revisions = append(revisions, ChatRevision{Message: chat.Message, EditedBy: chat.User, EditedAt: chat.CreatedAt})
+	}
+	revisions = append(revisions, ChatRevision{Revision: chat.Revision + 1, Message: newMessage, EditedBy: username, EditedAt: now})
+
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "message", Value: newMessage},
+			{Key: "edited_at", Value: now},
+			{Key: "revision", Value: chat.Revision + 1},
+		}},
+		{Key: "$push", Value: bson.D{{Key: "revisions", Value: bson.D{{Key: "$each", Value: revisions}}}}},
+	}
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var updated Chat
 	err = chatCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
 	if err == mongo.ErrNoDocuments {
 		// Conditions no longer met (window expired, deleted, or wrong user concurrently)
+		if time.Since(chat.CreatedAt) <= 5*time.Minute {
+			return nil, ErrEditConflict
+		}
 		return nil, ErrEditWindowExpired
 	}
 	if err != nil {
@@ -413,25 +725,33 @@ func EditChat(messageID, username, roomID, newMessage string) (*Chat, error) {
 }
 
 // DeleteChat soft-deletes a message by setting is_deleted=true and clearing message content.
+// The edit revisions are kept for moderation; they are never part of the message JSON.
 // #267: atomic FindOneAndUpdate eliminates TOCTOU race between ownership check and update.
-func DeleteChat(messageID, username string) error {
+// Returns the message as it was before deletion so callers can update its thread.
+// With an empty username the message is deleted regardless of its author; callers
+// must have checked the caller's moderation rights.
+func DeleteChat(messageID, username string) (*Chat, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	oid, err := primitive.ObjectIDFromHex(messageID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	filter := bson.D{
 		{Key: "_id", Value: oid},
-		{Key: "user", Value: username},
 		{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
 	}
-	update := bson.D{{Key: "$set", Value: bson.D{
-		{Key: "is_deleted", Value: true},
-		{Key: "message", Value: ""},
-	}}}
+	if username != "" {
+		filter = append(filter, bson.E{Key: "user", Value: username})
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "is_deleted", Value: true},
+			{Key: "message", Value: ""},
+		}},
+	}
 
 	var deleted Chat
 	err = chatCollection.FindOneAndUpdate(ctx, filter, update).Decode(&deleted)
@@ -440,18 +760,30 @@ func DeleteChat(messageID, username string) error {
 		var existing Chat
 		lookupErr := chatCollection.FindOne(ctx, bson.D{{Key: "_id", Value: oid}}).Decode(&existing)
 		if lookupErr != nil {
-			return lookupErr
+			return nil, lookupErr
 		}
-		return ErrForbidden
+		return nil, ErrForbidden
 	}
-	return err
+	if err != nil {
+		return nil, err
+	}
+	return &deleted, nil
 }
 
 // InsertChatWithReply saves a new chat message that replies to another message.
+// A sequence number is allocated if chatMessage.Seq is unset.
 func InsertChatWithReply(chatMessage ChatMessage) (*Chat, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if chatMessage.Seq == 0 {
+		seq, err := NextRoomSeq(chatMessage.RoomID)
+		if err != nil {
+			return nil, err
+		}
+		chatMessage.Seq = seq
+	}
+
 	chat := Chat{
 		CreatedAt: time.Now(),
 		RoomID:    chatMessage.RoomID,
@@ -460,6 +792,11 @@ func InsertChatWithReply(chatMessage ChatMessage) (*Chat, error) {
 		Message:   chatMessage.Message,
 		Owner:     chatMessage.Owner,
 		ReplyTo:   chatMessage.ReplyTo,
+		Seq:       chatMessage.Seq,
+		Mentions:  chatMessage.Mentions,
+	}
+	if t, err := time.Parse(time.RFC3339Nano, chatMessage.ExpiresAt); err == nil {
+		chat.ExpiresAt = &t
 	}
 
 	result, err := chatCollection.InsertOne(ctx, chat)
@@ -477,6 +814,7 @@ func SearchChatByRoom(roomID, query string, limit int64) ([]Chat, error) {
 
 	filter := bson.D{
 		{Key: "room_id", Value: roomID},
+		notExpired(),
 		{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
 		{Key: "$text", Value: bson.D{{Key: "$search", Value: query}}},
 	}
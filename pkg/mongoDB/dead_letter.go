@@ -0,0 +1,122 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeadLetter is a chat message that could not be persisted after all retries.
+type DeadLetter struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Chat     Chat               `json:"chat" bson:"chat"`
+	Error    string             `json:"error" bson:"error"`
+	Attempts int                `json:"attempts" bson:"attempts"`
+	FailedAt time.Time          `json:"failed_at" bson:"failed_at"`
+}
+
+var deadLetterCollection *mongo.Collection
+
+// InitDeadLetterCollection initializes the chat_dead_letters collection.
+func InitDeadLetterCollection(database *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := "chat_dead_letters"
+	database.CreateCollection(ctx, collection)
+	deadLetterCollection = database.Collection(collection)
+
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "failed_at", Value: -1}},
+	}
+	_, err := deadLetterCollection.Indexes().CreateOne(ctx, indexModel)
+	return err
+}
+
+// InsertDeadLetters parks messages that failed to persist after attempts tries.
+func InsertDeadLetters(messages []ChatMessage, cause error, attempts int) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	docs := make([]interface{}, len(messages))
+	for i, msg := range messages {
+		docs[i] = DeadLetter{
+			Chat:     chatFromMessage(msg),
+			Error:    cause.Error(),
+			Attempts: attempts,
+			FailedAt: now,
+		}
+	}
+	_, err := deadLetterCollection.InsertMany(ctx, docs)
+	return err
+}
+
+// FindDeadLetters returns a page of dead letters, newest first, and the total count.
+func FindDeadLetters(page, limit int64) ([]DeadLetter, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	total, err := deadLetterCollection.CountDocuments(ctx, bson.D{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "failed_at", Value: -1}}).
+		SetSkip((page - 1) * limit).
+		SetLimit(limit)
+	cur, err := deadLetterCollection.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cur.Close(ctx)
+
+	letters := []DeadLetter{}
+	for cur.Next(ctx) {
+		var dl DeadLetter
+		if err := cur.Decode(&dl); err != nil {
+			return nil, 0, err
+		}
+		letters = append(letters, dl)
+	}
+	return letters, total, cur.Err()
+}
+
+// ReplayDeadLetter inserts the parked message into the chats collection with
+// its original ID, timestamp and seq, then removes the dead letter.
+// Replaying a message that was already persisted is a no-op insert. If another
+// message holds its seq, the dead letter is kept and ErrSeqConflict returned.
+func ReplayDeadLetter(id string) (*Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	var dl DeadLetter
+	err = deadLetterCollection.FindOne(ctx, bson.D{{Key: "_id", Value: oid}}).Decode(&dl)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := insertChatDocs([]Chat{dl.Chat}); err != nil {
+		return nil, err
+	}
+	if _, err := deadLetterCollection.DeleteOne(ctx, bson.D{{Key: "_id", Value: oid}}); err != nil {
+		return nil, err
+	}
+	return &dl.Chat, nil
+}
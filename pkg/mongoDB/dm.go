@@ -0,0 +1,108 @@
+package mongodb
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RoomKindDM marks a room as a direct message conversation: a private,
+// unnamed room identified by its set of participants.
+const RoomKindDM = "dm"
+
+// IsDM reports whether the room is a direct message conversation.
+func (r Room) IsDM() bool {
+	return r.Kind == RoomKindDM
+}
+
+// DMParticipants returns the sorted, de-duplicated participant list.
+func DMParticipants(usernames []string) []string {
+	seen := make(map[string]bool, len(usernames))
+	participants := make([]string, 0, len(usernames))
+	for _, u := range usernames {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			participants = append(participants, u)
+		}
+	}
+	sort.Strings(participants)
+	return participants
+}
+
+// DMKey identifies the DM between participants, independent of order.
+func DMKey(participants []string) string {
+	return strings.Join(DMParticipants(participants), ",")
+}
+
+// FindOrCreateDM returns the DM whose participants are exactly participants,
+// creating it on first use. Participants who left an existing DM are added
+// back. created reports whether a new DM was inserted.
+func FindOrCreateDM(creator string, participants []string, maxMembers int) (*Room, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	participants = DMParticipants(participants)
+	key := DMKey(participants)
+
+	filter := bson.D{{Key: "dm_key", Value: key}}
+	update := bson.D{
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "kind", Value: RoomKindDM},
+			{Key: "dm_key", Value: key},
+			{Key: "description", Value: ""},
+			{Key: "is_public", Value: false},
+			{Key: "max_members", Value: maxMembers},
+			{Key: "created_by", Value: creator},
+			{Key: "created_at", Value: time.Now()},
+			{Key: "is_default", Value: false},
+		}},
+		{Key: "$addToSet", Value: bson.D{{Key: "members", Value: bson.D{{Key: "$each", Value: participants}}}}},
+	}
+
+	res, err := roomCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent request inserted the same DM; the retry updates it.
+		res, err = roomCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var room Room
+	if err := roomCollection.FindOne(ctx, filter).Decode(&room); err != nil {
+		return nil, false, err
+	}
+	return &room, res.UpsertedCount > 0, nil
+}
+
+// FindDMs returns the DMs username takes part in, newest first.
+func FindDMs(username string) ([]Room, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "kind", Value: RoomKindDM},
+		{Key: "members", Value: username},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cur, err := roomCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return []Room{}, err
+	}
+	defer cur.Close(ctx)
+
+	rooms := []Room{}
+	for cur.Next(ctx) {
+		var room Room
+		if err := cur.Decode(&room); err != nil {
+			return []Room{}, err
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, cur.Err()
+}
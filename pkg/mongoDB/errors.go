@@ -9,4 +9,14 @@ var (
 	ErrMessageDeleted     = errors.New("message deleted")
 	ErrNotFound           = errors.New("not found")
 	ErrDuplicateRoomName  = errors.New("duplicate room name")
+	ErrEditConflict       = errors.New("edit conflict")
+	ErrAlreadyPinned      = errors.New("already pinned")
+	ErrPinLimit           = errors.New("pin limit reached")
+	ErrMaxMembersTooLow   = errors.New("max members below member count")
+	ErrBanned             = errors.New("banned from room")
+	ErrRoomArchived       = errors.New("room archived")
+	ErrLegalHold          = errors.New("room under legal hold")
+	ErrPollConflict       = errors.New("poll closed or vote changed")
+	ErrReactionLimit      = errors.New("reaction limit reached")
+	ErrSeqConflict        = errors.New("room seq already taken")
 )
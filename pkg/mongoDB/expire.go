@@ -0,0 +1,89 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// notExpired matches messages without an expiry or whose expiry is still
+// ahead, so expired content is never read even before it is deleted.
+func notExpired() bson.E {
+	return bson.E{Key: "expires_at", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$lte", Value: time.Now()}}}}}
+}
+
+// DeleteExpiredChats deletes up to limit messages whose expires_at is at or
+// before now, along with their mentions and pins, and returns the deleted messages
+// (ID, room, seq and parent only) so their removal can be announced. Messages
+// of rooms under legal hold are kept until the hold is released.
+func DeleteExpiredChats(now time.Time, limit int64) ([]Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	held, err := heldRoomIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter := bson.D{{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: now}}}}
+	if len(held) > 0 {
+		filter = append(filter, bson.E{Key: "room_id", Value: bson.D{{Key: "$nin", Value: held}}})
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "expires_at", Value: 1}}).
+		SetLimit(limit).
+		SetProjection(bson.D{{Key: "room_id", Value: 1}, {Key: "seq", Value: 1}, {Key: "reply_to", Value: 1}})
+	cur, err := chatCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	expired := []Chat{}
+	if err := cur.All(ctx, &expired); err != nil {
+		return nil, err
+	}
+	if len(expired) == 0 {
+		return expired, nil
+	}
+
+	oids := make([]primitive.ObjectID, len(expired))
+	ids := make([]string, len(expired))
+	for i, chat := range expired {
+		oids[i] = chat.ID
+		ids[i] = chat.ID.Hex()
+	}
+	if _, err := chatCollection.DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: oids}}}}); err != nil {
+		return nil, err
+	}
+	if mentionCollection != nil {
+		mentionCollection.DeleteMany(ctx, bson.D{{Key: "message_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	}
+	roomCollection.UpdateMany(ctx,
+		bson.D{{Key: "pins.message_id", Value: bson.D{{Key: "$in", Value: ids}}}},
+		bson.D{{Key: "$pull", Value: bson.D{{Key: "pins", Value: bson.D{{Key: "message_id", Value: bson.D{{Key: "$in", Value: ids}}}}}}}},
+	)
+	return expired, nil
+}
+
+// heldRoomIDs returns the IDs of the rooms under legal hold.
+func heldRoomIDs(ctx context.Context) ([]string, error) {
+	cur, err := roomCollection.Find(ctx,
+		bson.D{{Key: "legal_hold", Value: true}},
+		options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var rooms []Room
+	if err := cur.All(ctx, &rooms); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(rooms))
+	for i, room := range rooms {
+		ids[i] = room.ID.Hex()
+	}
+	return ids, nil
+}
@@ -75,3 +75,40 @@ func FindFileByID(fileID string) (*FileMetadata, error) {
 	}
 	return &meta, nil
 }
+
+// FindRoomFilesBefore returns the files uploaded to roomID at or before t.
+func FindRoomFilesBefore(roomID string, t time.Time) ([]FileMetadata, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "room_id", Value: roomID},
+		{Key: "created_at", Value: bson.D{{Key: "$lte", Value: t}}},
+	}
+	cur, err := fileCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	files := []FileMetadata{}
+	if err := cur.All(ctx, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// DeleteFileMetadata removes the FileMetadata documents with the given IDs.
+func DeleteFileMetadata(ids []primitive.ObjectID) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result, err := fileCollection.DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
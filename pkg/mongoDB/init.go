@@ -22,5 +22,32 @@ func InitAll(database *mongo.Database) error {
 	if err := InitFileCollection(database); err != nil {
 		return err
 	}
+	if err := InitReadMarkerCollection(database); err != nil {
+		return err
+	}
+	if err := InitSequenceCollection(database); err != nil {
+		return err
+	}
+	if err := InitDeadLetterCollection(database); err != nil {
+		return err
+	}
+	if err := InitMentionCollection(database); err != nil {
+		return err
+	}
+	if err := InitInviteCollection(database); err != nil {
+		return err
+	}
+	if err := InitJoinRequestCollection(database); err != nil {
+		return err
+	}
+	if err := InitSanctionCollection(database); err != nil {
+		return err
+	}
+	if err := InitRoomPrefsCollection(database); err != nil {
+		return err
+	}
+	if err := InitScheduledCollection(database); err != nil {
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,239 @@
+package mongodb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RoomInvite is a revocable link that lets its holder join a room without the
+// room password.
+type RoomInvite struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Token     string             `json:"token" bson:"token"`
+	RoomID    string             `json:"room_id" bson:"room_id"`
+	CreatedBy string             `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	ExpiresAt *time.Time         `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	MaxUses   int                `json:"max_uses" bson:"max_uses"` // 0 = unlimited
+	Uses      int                `json:"uses" bson:"uses"`
+	// Invitee is the only user who may accept a personal invite; "" lets
+	// anyone holding the link join.
+	Invitee string `json:"invitee,omitempty" bson:"invitee,omitempty"`
+}
+
+var inviteCollection *mongo.Collection
+
+// InitInviteCollection initializes the room_invites collection. Expired
+// invites are removed by a TTL index on expires_at.
+func InitInviteCollection(database *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := "room_invites"
+	database.CreateCollection(ctx, collection)
+	inviteCollection = database.Collection(collection)
+
+	_, err := inviteCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "room_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "invitee", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// newInviteToken returns a random, URL-safe invite token.
+func newInviteToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CreateRoomInvite stores a new invite for roomID with a fresh token.
+// A nil expiresAt never expires; maxUses 0 allows unlimited uses.
+func CreateRoomInvite(roomID, createdBy string, expiresAt *time.Time, maxUses int) (*RoomInvite, error) {
+	return insertRoomInvite(RoomInvite{
+		RoomID:    roomID,
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+		MaxUses:   maxUses,
+	})
+}
+
+// CreateUserInvite stores a single-use invite to roomID that only invitee
+// may accept, valid until expiresAt.
+func CreateUserInvite(roomID, createdBy, invitee string, expiresAt time.Time) (*RoomInvite, error) {
+	return insertRoomInvite(RoomInvite{
+		RoomID:    roomID,
+		CreatedBy: createdBy,
+		ExpiresAt: &expiresAt,
+		MaxUses:   1,
+		Invitee:   invitee,
+	})
+}
+
+// insertRoomInvite stores invite with a fresh token and creation time.
+func insertRoomInvite(invite RoomInvite) (*RoomInvite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	token, err := newInviteToken()
+	if err != nil {
+		return nil, err
+	}
+	invite.Token = token
+	invite.CreatedAt = time.Now()
+	result, err := inviteCollection.InsertOne(ctx, invite)
+	if err != nil {
+		return nil, err
+	}
+	invite.ID = result.InsertedID.(primitive.ObjectID)
+	return &invite, nil
+}
+
+// FindRoomInvites returns roomID's invites that are still usable, newest first.
+func FindRoomInvites(roomID string) ([]RoomInvite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := append(bson.D{{Key: "room_id", Value: roomID}}, usableInviteFilter(time.Now())...)
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cur, err := inviteCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	invites := []RoomInvite{}
+	if err := cur.All(ctx, &invites); err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+// FindUserInvites returns the usable personal invites sent to username,
+// newest first.
+func FindUserInvites(username string) ([]RoomInvite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := append(bson.D{{Key: "invitee", Value: username}}, usableInviteFilter(time.Now())...)
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cur, err := inviteCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	invites := []RoomInvite{}
+	if err := cur.All(ctx, &invites); err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+// DeleteRoomInvite revokes an invite. Returns ErrNotFound if it does not exist.
+func DeleteRoomInvite(roomID, inviteID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	oid, err := primitive.ObjectIDFromHex(inviteID)
+	if err != nil {
+		return ErrNotFound
+	}
+	result, err := inviteCollection.DeleteOne(ctx, bson.D{{Key: "_id", Value: oid}, {Key: "room_id", Value: roomID}})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteRoomInvites revokes every invite of roomID and returns how many were removed.
+func DeleteRoomInvites(roomID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := inviteCollection.DeleteMany(ctx, bson.D{{Key: "room_id", Value: roomID}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// usableInviteFilter matches invites that have neither expired nor run out of uses at now.
+func usableInviteFilter(now time.Time) bson.D {
+	return bson.D{{Key: "$and", Value: bson.A{
+		bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "expires_at", Value: bson.D{{Key: "$exists", Value: false}}}},
+			bson.D{{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: now}}}},
+		}}},
+		bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "max_uses", Value: 0}},
+			bson.D{{Key: "$expr", Value: bson.D{{Key: "$lt", Value: bson.A{"$uses", "$max_uses"}}}}},
+		}}},
+	}}}
+}
+
+// ClaimRoomInvite atomically uses up one use of the invite with token for
+// username. Returns ErrNotFound if the token is unknown, expired, exhausted
+// or a personal invite for someone else.
+func ClaimRoomInvite(token, username string) (*RoomInvite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := append(bson.D{
+		{Key: "token", Value: token},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "invitee", Value: bson.D{{Key: "$exists", Value: false}}}},
+			bson.D{{Key: "invitee", Value: username}},
+		}},
+	}, usableInviteFilter(time.Now())...)
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "uses", Value: 1}}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var invite RoomInvite
+	err := inviteCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&invite)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// ReleaseRoomInvite gives back a use claimed by ClaimRoomInvite when the join
+// it was claimed for did not happen.
+func ReleaseRoomInvite(inviteID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := inviteCollection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: inviteID}, {Key: "uses", Value: bson.D{{Key: "$gt", Value: 0}}}},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "uses", Value: -1}}}},
+	)
+	return err
+}
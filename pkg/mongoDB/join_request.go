@@ -0,0 +1,222 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Join policies decide how a user who is not yet a member gets into a room.
+const (
+	JoinPolicyOpen       = "open"        // anyone may join
+	JoinPolicyPassword   = "password"    // joining requires the room password
+	JoinPolicyApproval   = "approval"    // joining creates a request a moderator decides
+	JoinPolicyInviteOnly = "invite_only" // only invite links let users in
+)
+
+// Join request states.
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestRejected = "rejected"
+)
+
+// EffectiveJoinPolicy returns the room's join policy. Rooms created before
+// join policies existed require their password if they are private and have one.
+func (r Room) EffectiveJoinPolicy() string {
+	if r.JoinPolicy != "" {
+		return r.JoinPolicy
+	}
+	if !r.IsPublic && r.Password != "" {
+		return JoinPolicyPassword
+	}
+	return JoinPolicyOpen
+}
+
+// JoinRequest is a user's request to join an approval-policy room.
+type JoinRequest struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	RoomID    string             `json:"room_id" bson:"room_id"`
+	Username  string             `json:"username" bson:"username"`
+	Status    string             `json:"status" bson:"status"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	DecidedBy string             `json:"decided_by,omitempty" bson:"decided_by,omitempty"`
+	DecidedAt *time.Time         `json:"decided_at,omitempty" bson:"decided_at,omitempty"`
+}
+
+var joinRequestCollection *mongo.Collection
+
+// InitJoinRequestCollection initializes the room_join_requests collection.
+// A user has at most one pending request per room.
+func InitJoinRequestCollection(database *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := "room_join_requests"
+	database.CreateCollection(ctx, collection)
+	joinRequestCollection = database.Collection(collection)
+
+	_, err := joinRequestCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "room_id", Value: 1},
+				{Key: "username", Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(
+				bson.D{{Key: "status", Value: JoinRequestPending}},
+			),
+		},
+		{
+			Keys: bson.D{
+				{Key: "room_id", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "created_at", Value: 1},
+			},
+		},
+	})
+	return err
+}
+
+// CreateJoinRequest files a pending request for username to join roomID. If
+// one is already pending it is returned instead.
+func CreateJoinRequest(roomID, username string) (*JoinRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := JoinRequest{
+		RoomID:    roomID,
+		Username:  username,
+		Status:    JoinRequestPending,
+		CreatedAt: time.Now(),
+	}
+	result, err := joinRequestCollection.InsertOne(ctx, req)
+	if mongo.IsDuplicateKeyError(err) {
+		var existing JoinRequest
+		filter := bson.D{
+			{Key: "room_id", Value: roomID},
+			{Key: "username", Value: username},
+			{Key: "status", Value: JoinRequestPending},
+		}
+		if err := joinRequestCollection.FindOne(ctx, filter).Decode(&existing); err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	req.ID = result.InsertedID.(primitive.ObjectID)
+	return &req, nil
+}
+
+// FindPendingJoinRequests returns roomID's pending requests, oldest first.
+func FindPendingJoinRequests(roomID string) ([]JoinRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "room_id", Value: roomID},
+		{Key: "status", Value: JoinRequestPending},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cur, err := joinRequestCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	requests := []JoinRequest{}
+	if err := cur.All(ctx, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// DecideJoinRequest moves a pending request of roomID to status (approved or
+// rejected). Returns ErrNotFound if the request is unknown or already decided.
+func DecideJoinRequest(roomID, requestID, status, decidedBy string) (*JoinRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	oid, err := primitive.ObjectIDFromHex(requestID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	filter := bson.D{
+		{Key: "_id", Value: oid},
+		{Key: "room_id", Value: roomID},
+		{Key: "status", Value: JoinRequestPending},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: status},
+		{Key: "decided_by", Value: decidedBy},
+		{Key: "decided_at", Value: time.Now()},
+	}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var req JoinRequest
+	err = joinRequestCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&req)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// ReopenJoinRequest puts a decided request back to pending, for when an
+// approval could not be carried out.
+func ReopenJoinRequest(requestID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := joinRequestCollection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: requestID}},
+		bson.D{
+			{Key: "$set", Value: bson.D{{Key: "status", Value: JoinRequestPending}}},
+			{Key: "$unset", Value: bson.D{
+				{Key: "decided_by", Value: ""},
+				{Key: "decided_at", Value: ""},
+			}},
+		},
+	)
+	return err
+}
+
+// RejectJoinRequest turns an approved request into a rejected one, for when
+// the approval can never be carried out.
+func RejectJoinRequest(requestID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := joinRequestCollection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: requestID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: JoinRequestRejected}}}},
+	)
+	return err
+}
+
+// RejectPendingJoinRequests rejects username's pending requests to join roomID,
+// e.g. once they are banned from it.
+func RejectPendingJoinRequests(roomID, username, decidedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "room_id", Value: roomID},
+		{Key: "username", Value: username},
+		{Key: "status", Value: JoinRequestPending},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: JoinRequestRejected},
+		{Key: "decided_by", Value: decidedBy},
+		{Key: "decided_at", Value: time.Now()},
+	}}}
+	_, err := joinRequestCollection.UpdateMany(ctx, filter, update)
+	return err
+}
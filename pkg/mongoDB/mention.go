@@ -0,0 +1,200 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MessageMentions is the structured mention data parsed from a message.
+type MessageMentions struct {
+	Users []string `json:"users,omitempty" bson:"users,omitempty"`
+	Here  bool     `json:"here,omitempty" bson:"here,omitempty"`
+	Room  bool     `json:"room,omitempty" bson:"room,omitempty"`
+}
+
+// Mention kinds, in order of precedence when a user is mentioned several ways.
+const (
+	MentionKindUser = "user"
+	MentionKindHere = "here"
+	MentionKindRoom = "room"
+)
+
+// Mention is one entry in a user's mention inbox.
+type Mention struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Username  string             `json:"username" bson:"username"`
+	RoomID    string             `json:"room_id" bson:"room_id"`
+	MessageID string             `json:"message_id" bson:"message_id"`
+	From      string             `json:"from" bson:"from"`
+	Kind      string             `json:"kind" bson:"kind"`
+	Preview   string             `json:"preview" bson:"preview"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	Read      bool               `json:"read" bson:"read"`
+}
+
+var mentionCollection *mongo.Collection
+
+// InitMentionCollection initializes the mentions collection.
+// A user is mentioned at most once per message.
+func InitMentionCollection(database *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := "mentions"
+	database.CreateCollection(ctx, collection)
+	mentionCollection = database.Collection(collection)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "username", Value: 1},
+				{Key: "created_at", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "username", Value: 1},
+				{Key: "message_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "message_id", Value: 1}},
+		},
+	}
+	_, err := mentionCollection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// InsertMentions stores mentions, silently skipping users already mentioned in
+// the same message.
+func InsertMentions(mentions []Mention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	docs := make([]interface{}, len(mentions))
+	for i, m := range mentions {
+		docs[i] = m
+	}
+	_, err := mentionCollection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// FindMentions returns up to limit mentions of username, newest first. When
+// before is non-zero only older mentions are returned; beforeID, the last
+// mention of the previous page, also pages through mentions created at exactly
+// before. unreadOnly skips read ones.
+func FindMentions(username string, before time.Time, beforeID primitive.ObjectID, limit int64, unreadOnly bool) ([]Mention, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{{Key: "username", Value: username}}
+	switch {
+	case before.IsZero():
+	case beforeID.IsZero():
+		filter = append(filter, bson.E{Key: "created_at", Value: bson.D{{Key: "$lt", Value: before}}})
+	default:
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "created_at", Value: bson.D{{Key: "$lt", Value: before}}}},
+			bson.D{{Key: "created_at", Value: before}, {Key: "_id", Value: bson.D{{Key: "$lt", Value: beforeID}}}},
+		}})
+	}
+	if unreadOnly {
+		filter = append(filter, bson.E{Key: "read", Value: false})
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(limit)
+
+	cur, err := mentionCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	mentions := []Mention{}
+	for cur.Next(ctx) {
+		var m Mention
+		if err := cur.Decode(&m); err != nil {
+			return nil, err
+		}
+		mentions = append(mentions, m)
+	}
+	return mentions, cur.Err()
+}
+
+// CountUnreadMentions counts unread mentions of username.
+func CountUnreadMentions(username string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "username", Value: username},
+		{Key: "read", Value: false},
+	}
+	return mentionCollection.CountDocuments(ctx, filter)
+}
+
+// MarkMentionsRead marks the given mentions of username as read, or all of
+// them when ids is empty. Returns the number of mentions changed.
+func MarkMentionsRead(username string, ids []string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "username", Value: username},
+		{Key: "read", Value: false},
+	}
+	if len(ids) > 0 {
+		oids := make([]primitive.ObjectID, 0, len(ids))
+		for _, id := range ids {
+			if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+				oids = append(oids, oid)
+			}
+		}
+		filter = append(filter, bson.E{Key: "_id", Value: bson.D{{Key: "$in", Value: oids}}})
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "read", Value: true}}}}
+	result, err := mentionCollection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// MarkRoomMentionsRead marks username's mentions in roomID created at or before
+// upTo as read, so reading a room also clears its mentions.
+func MarkRoomMentionsRead(username, roomID string, upTo time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "username", Value: username},
+		{Key: "room_id", Value: roomID},
+		{Key: "read", Value: false},
+		{Key: "created_at", Value: bson.D{{Key: "$lte", Value: upTo}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "read", Value: true}}}}
+	_, err := mentionCollection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// DeleteMessageMentions removes every inbox entry for messageID, so a deleted
+// message's preview is no longer shown.
+func DeleteMessageMentions(messageID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := mentionCollection.DeleteMany(ctx, bson.D{{Key: "message_id", Value: messageID}})
+	return err
+}
@@ -0,0 +1,83 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RoomPin records a pinned message on its room.
+type RoomPin struct {
+	MessageID string    `json:"message_id" bson:"message_id"`
+	PinnedBy  string    `json:"pinned_by" bson:"pinned_by"`
+	PinnedAt  time.Time `json:"pinned_at" bson:"pinned_at"`
+}
+
+// AddPin appends pin to the room's pins unless the message is already pinned or
+// the room already holds maxPins pins. Both conditions are checked atomically.
+func AddPin(roomID string, pin RoomPin, maxPins int) (*Room, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: objID},
+		{Key: "pins.message_id", Value: bson.D{{Key: "$ne", Value: pin.MessageID}}},
+		{Key: fmt.Sprintf("pins.%d", maxPins-1), Value: bson.D{{Key: "$exists", Value: false}}},
+	}
+	update := bson.D{{Key: "$push", Value: bson.D{{Key: "pins", Value: pin}}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var room Room
+	err = roomCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&room)
+	if err == mongo.ErrNoDocuments {
+		existing, findErr := FindRoomByID(roomID)
+		if findErr != nil {
+			return nil, ErrNotFound
+		}
+		for _, p := range existing.Pins {
+			if p.MessageID == pin.MessageID {
+				return nil, ErrAlreadyPinned
+			}
+		}
+		return nil, ErrPinLimit
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// RemovePin unpins messageID from the room. Returns ErrNotFound if it was not pinned.
+func RemovePin(roomID, messageID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: objID},
+		{Key: "pins.message_id", Value: messageID},
+	}
+	update := bson.D{{Key: "$pull", Value: bson.D{{Key: "pins", Value: bson.D{{Key: "message_id", Value: messageID}}}}}}
+	result, err := roomCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
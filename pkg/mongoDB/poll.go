@@ -0,0 +1,213 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PollOption is one answer of a poll with its live tally.
+type PollOption struct {
+	Text   string   `json:"text" bson:"text"`
+	Votes  int      `json:"votes" bson:"votes"`
+	Voters []string `json:"voters,omitempty" bson:"voters,omitempty"` // hidden from clients in anonymous polls
+}
+
+// Poll is the question and options of a poll message. Votes are counted with
+// atomic updates on the chat document.
+type Poll struct {
+	Question  string       `json:"question" bson:"question"`
+	Options   []PollOption `json:"options" bson:"options"`
+	Multiple  bool         `json:"multiple" bson:"multiple"`
+	Anonymous bool         `json:"anonymous" bson:"anonymous"`
+	ClosesAt  *time.Time   `json:"closes_at,omitempty" bson:"closes_at,omitempty"`
+	ClosedAt  *time.Time   `json:"closed_at,omitempty" bson:"closed_at,omitempty"`
+	// Derived when the poll is sent to clients; not stored.
+	Closed      bool `json:"closed" bson:"-"`
+	TotalVoters int  `json:"total_voters" bson:"-"`
+}
+
+// IsClosed reports whether the poll no longer accepts votes at now.
+func (p Poll) IsClosed(now time.Time) bool {
+	return p.Closed || p.ClosedAt != nil || (p.ClosesAt != nil && !now.Before(*p.ClosesAt))
+}
+
+// VotedFor returns the options username voted for, in option order.
+func (p Poll) VotedFor(username string) []int {
+	voted := []int{}
+	for i, opt := range p.Options {
+		for _, v := range opt.Voters {
+			if v == username {
+				voted = append(voted, i)
+				break
+			}
+		}
+	}
+	return voted
+}
+
+// MarshalJSON fills in Closed and TotalVoters and leaves out who voted for
+// what in anonymous polls.
+func (p Poll) MarshalJSON() ([]byte, error) {
+	type plain Poll // without this method
+	out := plain(p)
+	out.Closed = p.IsClosed(time.Now())
+
+	// A poll decoded from JSON has no voters left to count; keep its total.
+	voters := map[string]bool{}
+	for _, opt := range p.Options {
+		for _, v := range opt.Voters {
+			voters[v] = true
+		}
+	}
+	if len(voters) > 0 {
+		out.TotalVoters = len(voters)
+	}
+
+	if p.Anonymous {
+		out.Options = make([]PollOption, len(p.Options))
+		for i, opt := range p.Options {
+			out.Options[i] = PollOption{Text: opt.Text, Votes: opt.Votes}
+		}
+	}
+	return json.Marshal(out)
+}
+
+// pollOptionField is the path of a field of option i of a chat's poll.
+func pollOptionField(i int, field string) string {
+	return "poll.options." + strconv.Itoa(i) + "." + field
+}
+
+// openPollFilter matches the open poll messageID in roomID.
+func openPollFilter(messageID, roomID string, now time.Time) (bson.D, error) {
+	oid, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	return bson.D{
+		{Key: "_id", Value: oid},
+		{Key: "room_id", Value: roomID},
+		{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
+		{Key: "poll", Value: bson.D{{Key: "$exists", Value: true}}},
+		{Key: "poll.closed_at", Value: bson.D{{Key: "$exists", Value: false}}},
+		{Key: "poll.closes_at", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$lte", Value: now}}}}},
+		notExpired(),
+	}, nil
+}
+
+// updatePoll applies update to the poll matched by filter and returns the
+// updated message, or ErrPollConflict if it no longer matches.
+func updatePoll(filter, update bson.D) (*Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated Chat
+	err := chatCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrPollConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// VotePoll records username's vote for option on the open poll messageID in
+// roomID. In a single-choice poll the vote moves away from the option in
+// from, which must be the user's current vote or -1 if they have none.
+// Returns ErrPollConflict if the poll closed or the user's votes changed
+// meanwhile.
+func VotePoll(messageID, roomID, username string, option, from int, now time.Time) (*Chat, error) {
+	filter, err := openPollFilter(messageID, roomID, now)
+	if err != nil {
+		return nil, err
+	}
+	inc := bson.D{{Key: pollOptionField(option, "votes"), Value: 1}}
+	update := bson.D{{Key: "$addToSet", Value: bson.D{{Key: pollOptionField(option, "voters"), Value: username}}}}
+
+	if from >= 0 {
+		filter = append(filter,
+			bson.E{Key: "poll.multiple", Value: false},
+			bson.E{Key: pollOptionField(from, "voters"), Value: username},
+		)
+		inc = append(inc, bson.E{Key: pollOptionField(from, "votes"), Value: -1})
+		update = append(update, bson.E{Key: "$pull", Value: bson.D{{Key: pollOptionField(from, "voters"), Value: username}}})
+	} else {
+		// A single-choice voter must not hold a vote elsewhere; a
+		// multiple-choice voter must not hold this one already.
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{
+				{Key: "poll.multiple", Value: false},
+				{Key: "poll.options.voters", Value: bson.D{{Key: "$ne", Value: username}}},
+			},
+			bson.D{
+				{Key: "poll.multiple", Value: true},
+				{Key: pollOptionField(option, "voters"), Value: bson.D{{Key: "$ne", Value: username}}},
+			},
+		}})
+	}
+	update = append(update, bson.E{Key: "$inc", Value: inc})
+	return updatePoll(filter, update)
+}
+
+// UnvotePoll withdraws username's vote for option on the open poll messageID
+// in roomID. Returns ErrPollConflict if the poll closed or the user has no
+// such vote.
+func UnvotePoll(messageID, roomID, username string, option int, now time.Time) (*Chat, error) {
+	filter, err := openPollFilter(messageID, roomID, now)
+	if err != nil {
+		return nil, err
+	}
+	filter = append(filter, bson.E{Key: pollOptionField(option, "voters"), Value: username})
+	update := bson.D{
+		{Key: "$inc", Value: bson.D{{Key: pollOptionField(option, "votes"), Value: -1}}},
+		{Key: "$pull", Value: bson.D{{Key: pollOptionField(option, "voters"), Value: username}}},
+	}
+	return updatePoll(filter, update)
+}
+
+// CloseDuePolls marks up to limit polls whose close time is at or before now
+// as closed and returns them with their final tallies.
+func CloseDuePolls(now time.Time, limit int64) ([]Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "poll.closes_at", Value: bson.D{{Key: "$lte", Value: now}}},
+		{Key: "poll.closed_at", Value: bson.D{{Key: "$exists", Value: false}}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "poll.closes_at", Value: 1}}).SetLimit(limit)
+	cur, err := chatCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	due := []Chat{}
+	if err := cur.All(ctx, &due); err != nil {
+		return nil, err
+	}
+
+	closed := make([]Chat, 0, len(due))
+	for _, chat := range due {
+		result, err := chatCollection.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: chat.ID}, {Key: "poll.closed_at", Value: bson.D{{Key: "$exists", Value: false}}}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "poll.closed_at", Value: now}}}},
+		)
+		if err != nil {
+			return closed, err
+		}
+		// Another server closed it first.
+		if result.ModifiedCount == 0 {
+			continue
+		}
+		chat.Poll.ClosedAt = &now
+		closed = append(closed, chat)
+	}
+	return closed, nil
+}
@@ -0,0 +1,108 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddReaction records username's reaction with emoji on a message in roomID.
+// $addToSet makes repeated reactions with the same emoji idempotent. A new
+// emoji is only added while the message has fewer than maxKinds; both
+// conditions are checked atomically. Returns ErrNotFound if the message does
+// not exist in the room or is deleted, and ErrReactionLimit if it is full.
+func AddReaction(messageID, roomID, username, emoji string, maxKinds int) (*Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	oid, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	field := "reactions." + emoji
+	filter := bson.D{
+		{Key: "_id", Value: oid},
+		{Key: "room_id", Value: roomID},
+		{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: field, Value: bson.D{{Key: "$exists", Value: true}}}},
+			bson.D{{Key: "$expr", Value: bson.D{{Key: "$lt", Value: bson.A{
+				bson.D{{Key: "$size", Value: bson.D{{Key: "$objectToArray", Value: bson.D{
+					{Key: "$ifNull", Value: bson.A{"$reactions", bson.D{}}},
+				}}}}},
+				maxKinds,
+			}}}}},
+		}},
+	}
+	update := bson.D{{Key: "$addToSet", Value: bson.D{
+		{Key: field, Value: username},
+	}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated Chat
+	err = chatCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		count, countErr := chatCollection.CountDocuments(ctx, filter[:3])
+		if countErr != nil {
+			return nil, countErr
+		}
+		if count == 0 {
+			return nil, ErrNotFound
+		}
+		return nil, ErrReactionLimit
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// RemoveReaction removes username's reaction with emoji from a message in roomID.
+// The emoji key is dropped entirely once no users are left on it.
+func RemoveReaction(messageID, roomID, username, emoji string) (*Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	oid, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	field := "reactions." + emoji
+	filter := bson.D{
+		{Key: "_id", Value: oid},
+		{Key: "room_id", Value: roomID},
+		{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
+	}
+	update := bson.D{{Key: "$pull", Value: bson.D{{Key: field, Value: username}}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated Chat
+	err = chatCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if users, ok := updated.Reactions[emoji]; ok && len(users) == 0 {
+		// Only unset while still empty so a concurrent AddReaction is not lost.
+		if _, err := chatCollection.UpdateOne(ctx,
+			bson.D{
+				{Key: "_id", Value: oid},
+				{Key: field, Value: bson.D{{Key: "$size", Value: 0}}},
+			},
+			bson.D{{Key: "$unset", Value: bson.D{{Key: field, Value: ""}}}},
+		); err != nil {
+			return nil, err
+		}
+		delete(updated.Reactions, emoji)
+	}
+	return &updated, nil
+}
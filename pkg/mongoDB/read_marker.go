@@ -0,0 +1,185 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReadMarker records the last message a user has read in a room.
+type ReadMarker struct {
+	ID                primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	Username          string             `json:"username" bson:"username"`
+	RoomID            string             `json:"room_id" bson:"room_id"`
+	LastReadMessageID string             `json:"last_read_message_id" bson:"last_read_message_id"`
+	LastReadAt        time.Time          `json:"last_read_at" bson:"last_read_at"` // created_at of the last read message
+	UpdatedAt         time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+var readMarkerCollection *mongo.Collection
+
+// InitReadMarkerCollection initializes the read_markers collection with a unique (username, room_id) index.
+func InitReadMarkerCollection(database *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := "read_markers"
+	database.CreateCollection(ctx, collection)
+	readMarkerCollection = database.Collection(collection)
+
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "username", Value: 1},
+			{Key: "room_id", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := readMarkerCollection.Indexes().CreateOne(ctx, indexModel)
+	return err
+}
+
+// SetReadMarker moves the user's read marker in roomID to messageID, whose creation
+// time is readAt. The marker only ever moves forward: if the stored marker already
+// points at a newer message nothing changes and advanced is false.
+func SetReadMarker(username, roomID, messageID string, readAt time.Time) (advanced bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "username", Value: username},
+		{Key: "room_id", Value: roomID},
+		{Key: "last_read_at", Value: bson.D{{Key: "$lt", Value: readAt}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "last_read_message_id", Value: messageID},
+		{Key: "last_read_at", Value: readAt},
+		{Key: "updated_at", Value: time.Now()},
+	}}}
+
+	_, err = readMarkerCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// A marker exists but is already at or past readAt.
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// FindReadMarker returns the read marker of username in roomID.
+// A zero ReadMarker is returned when the user has never marked the room as read.
+func FindReadMarker(username, roomID string) (ReadMarker, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var m ReadMarker
+	filter := bson.D{
+		{Key: "username", Value: username},
+		{Key: "room_id", Value: roomID},
+	}
+	err := readMarkerCollection.FindOne(ctx, filter).Decode(&m)
+	if err == mongo.ErrNoDocuments {
+		return ReadMarker{}, nil
+	}
+	return m, err
+}
+
+// FindReadMarkers returns all read markers of username keyed by room ID.
+func FindReadMarkers(username string) (map[string]ReadMarker, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cur, err := readMarkerCollection.Find(ctx, bson.D{{Key: "username", Value: username}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	markers := make(map[string]ReadMarker)
+	for cur.Next(ctx) {
+		var m ReadMarker
+		if err := cur.Decode(&m); err != nil {
+			return nil, err
+		}
+		markers[m.RoomID] = m
+	}
+	return markers, cur.Err()
+}
+
+// CountUnread counts messages in roomID created after `after` that were not sent by
+// username. A zero `after` counts the whole room. The count stops at limit.
+func CountUnread(roomID, username string, after time.Time, limit int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "room_id", Value: roomID},
+		{Key: "user", Value: bson.D{{Key: "$ne", Value: username}}},
+		notExpired(),
+		{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
+	}
+	if !after.IsZero() {
+		filter = append(filter, bson.E{Key: "created_at", Value: bson.D{{Key: "$gt", Value: after}}})
+	}
+	return chatCollection.CountDocuments(ctx, filter, options.Count().SetLimit(limit))
+}
+
+// CountUnreadRooms counts unread messages for username in several rooms with a
+// single aggregation. after maps each room ID to the creation time of the last
+// message read there, zero for a room never read. Rooms without unread
+// messages are absent from the result; counts are capped at limit.
+func CountUnreadRooms(username string, after map[string]time.Time, limit int64) (map[string]int64, error) {
+	counts := make(map[string]int64, len(after))
+	if len(after) == 0 {
+		return counts, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rooms := make(bson.A, 0, len(after))
+	for roomID, at := range after {
+		cond := bson.D{{Key: "room_id", Value: roomID}}
+		if !at.IsZero() {
+			cond = append(cond, bson.E{Key: "created_at", Value: bson.D{{Key: "$gt", Value: at}}})
+		}
+		rooms = append(rooms, cond)
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{
+			{Key: "$or", Value: rooms},
+			{Key: "user", Value: bson.D{{Key: "$ne", Value: username}}},
+			notExpired(),
+			{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
+		}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$room_id"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+
+	cur, err := chatCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var row struct {
+			RoomID string `bson:"_id"`
+			Count  int64  `bson:"count"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, err
+		}
+		if row.Count > limit {
+			row.Count = limit
+		}
+		counts[row.RoomID] = row.Count
+	}
+	return counts, cur.Err()
+}
@@ -0,0 +1,295 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RetentionPolicy limits how long a room keeps its messages. Zero fields keep
+// messages forever.
+type RetentionPolicy struct {
+	Days        int // delete messages older than this many days
+	MaxMessages int // keep only this many of the newest messages
+}
+
+// IsZero reports whether p keeps every message.
+func (p RetentionPolicy) IsZero() bool {
+	return p.Days <= 0 && p.MaxMessages <= 0
+}
+
+// EffectiveRetention returns the room's retention policy: its own settings,
+// falling back field by field to the server-wide def. Rooms under legal hold
+// keep everything.
+func (r Room) EffectiveRetention(def RetentionPolicy) RetentionPolicy {
+	if r.LegalHold {
+		return RetentionPolicy{}
+	}
+	p := RetentionPolicy{Days: r.RetentionDays, MaxMessages: r.RetentionMaxMessages}
+	if p.Days <= 0 {
+		p.Days = def.Days
+	}
+	if p.MaxMessages <= 0 {
+		p.MaxMessages = def.MaxMessages
+	}
+	return p
+}
+
+// PurgeResult describes the messages removed from a room by one purge.
+type PurgeResult struct {
+	Deleted     int64
+	ThroughSeq  int64     // highest seq removed
+	PurgedUntil time.Time // newest created_at removed
+	Threads     []Chat    // surviving parents whose reply summary changed
+}
+
+// FindRetentionRooms returns the rooms the retention purger must visit: every
+// room not under legal hold when a server-wide policy applies, otherwise only
+// rooms with a policy of their own.
+func FindRetentionRooms(serverDefault bool) ([]Room, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{{Key: "legal_hold", Value: bson.D{{Key: "$ne", Value: true}}}}
+	if !serverDefault {
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "retention_days", Value: bson.D{{Key: "$gt", Value: 0}}}},
+			bson.D{{Key: "retention_max_messages", Value: bson.D{{Key: "$gt", Value: 0}}}},
+		}})
+	}
+	opts := options.Find().SetProjection(bson.D{
+		{Key: "retention_days", Value: 1},
+		{Key: "retention_max_messages", Value: 1},
+		{Key: "legal_hold", Value: 1},
+	})
+
+	cur, err := roomCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	rooms := []Room{}
+	if err := cur.All(ctx, &rooms); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// PurgeRoomMessages deletes the messages of roomID that policy no longer
+// keeps, along with their mentions and pins, recomputes the reply summaries of
+// threads that lost replies and records how far the room has been purged.
+// Returns nil when nothing was deleted.
+func PurgeRoomMessages(roomID string, policy RetentionPolicy, now time.Time) (*PurgeResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	expired := bson.A{}
+	if policy.Days > 0 {
+		cutoff := now.AddDate(0, 0, -policy.Days)
+		expired = append(expired, bson.D{{Key: "created_at", Value: bson.D{{Key: "$lt", Value: cutoff}}}})
+	}
+	if policy.MaxMessages > 0 {
+		// The newest message beyond the kept ones; it and everything before it go.
+		var last Chat
+		err := chatCollection.FindOne(ctx,
+			bson.D{{Key: "room_id", Value: roomID}, {Key: "seq", Value: bson.D{{Key: "$exists", Value: true}}}},
+			options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}}).SetSkip(int64(policy.MaxMessages)),
+		).Decode(&last)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		if err == nil {
+			expired = append(expired, bson.D{{Key: "seq", Value: bson.D{{Key: "$lte", Value: last.Seq}}}})
+		}
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+	filter := bson.D{{Key: "room_id", Value: roomID}, {Key: "$or", Value: expired}}
+
+	var newest Chat
+	err := chatCollection.FindOne(ctx, filter, options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}, {Key: "created_at", Value: -1}})).Decode(&newest)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	replyFilter := append(bson.D{{Key: "reply_to", Value: bson.D{{Key: "$nin", Value: bson.A{nil, ""}}}}}, filter...)
+	parentIDs, err := chatCollection.Distinct(ctx, "reply_to", replyFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := chatCollection.DeleteMany(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	result := &PurgeResult{Deleted: deleted.DeletedCount, ThroughSeq: newest.Seq, PurgedUntil: newest.CreatedAt}
+
+	if mentionCollection != nil {
+		mentionCollection.DeleteMany(ctx, bson.D{
+			{Key: "room_id", Value: roomID},
+			{Key: "created_at", Value: bson.D{{Key: "$lte", Value: result.PurgedUntil}}},
+		})
+	}
+	if err := markRoomPurged(ctx, roomID, result); err != nil {
+		return result, err
+	}
+	if len(parentIDs) > 0 {
+		if result.Threads, err = resummarizeThreads(ctx, roomID, parentIDs); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// resummarizeThreads recomputes the reply summary of parentIDs from the
+// replies they have left and returns the parents that still exist. Replies that
+// expired but were not swept yet still count; the expirer takes them off.
+func resummarizeThreads(ctx context.Context, roomID string, parentIDs []interface{}) ([]Chat, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{
+			{Key: "room_id", Value: roomID},
+			{Key: "reply_to", Value: bson.D{{Key: "$in", Value: parentIDs}}},
+			{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
+		}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$reply_to"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "last", Value: bson.D{{Key: "$max", Value: "$created_at"}}},
+		}}},
+	}
+	cur, err := chatCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var summaries []struct {
+		ParentID string    `bson:"_id"`
+		Count    int       `bson:"count"`
+		Last     time.Time `bson:"last"`
+	}
+	if err := cur.All(ctx, &summaries); err != nil {
+		return nil, err
+	}
+	remaining := make(map[string]int, len(summaries))
+	last := make(map[string]time.Time, len(summaries))
+	for _, summary := range summaries {
+		remaining[summary.ParentID] = summary.Count
+		last[summary.ParentID] = summary.Last
+	}
+
+	ids := make([]string, 0, len(parentIDs))
+	for _, v := range parentIDs {
+		id, ok := v.(string)
+		if !ok {
+			continue
+		}
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		update := bson.D{{Key: "$unset", Value: bson.D{
+			{Key: "reply_count", Value: ""},
+			{Key: "last_reply_at", Value: ""},
+		}}}
+		if count := remaining[id]; count > 0 {
+			update = bson.D{{Key: "$set", Value: bson.D{
+				{Key: "reply_count", Value: count},
+				{Key: "last_reply_at", Value: last[id]},
+			}}}
+		}
+		if _, err := chatCollection.UpdateOne(ctx, bson.D{{Key: "_id", Value: oid}, {Key: "room_id", Value: roomID}}, update); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	parents, err := FindChatsByIDs(roomID, ids)
+	if err != nil {
+		return nil, err
+	}
+	threads := make([]Chat, 0, len(parents))
+	for _, parent := range parents {
+		threads = append(threads, parent)
+	}
+	return threads, nil
+}
+
+// markRoomPurged advances the room's purge watermark and drops pins of
+// messages that no longer exist.
+func markRoomPurged(ctx context.Context, roomID string, result *PurgeResult) error {
+	objID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return ErrNotFound
+	}
+	filter := bson.D{{Key: "_id", Value: objID}}
+	update := bson.D{{Key: "$max", Value: bson.D{
+		{Key: "purged_through_seq", Value: result.ThroughSeq},
+		{Key: "purged_until", Value: result.PurgedUntil},
+	}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var room Room
+	if err := roomCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&room); err != nil {
+		return err
+	}
+	if len(room.Pins) == 0 {
+		return nil
+	}
+
+	pinned := make([]string, 0, len(room.Pins))
+	for _, pin := range room.Pins {
+		pinned = append(pinned, pin.MessageID)
+	}
+	existing, err := FindChatsByIDs(roomID, pinned)
+	if err != nil {
+		return err
+	}
+	gone := bson.A{}
+	for _, id := range pinned {
+		if _, ok := existing[id]; !ok {
+			gone = append(gone, id)
+		}
+	}
+	if len(gone) == 0 {
+		return nil
+	}
+	_, err = roomCollection.UpdateOne(ctx, filter, bson.D{{Key: "$pull", Value: bson.D{
+		{Key: "pins", Value: bson.D{{Key: "message_id", Value: bson.D{{Key: "$in", Value: gone}}}}},
+	}}})
+	return err
+}
+
+// SetRoomLegalHold places roomID under legal hold, which suspends retention
+// purging, message expiry and room deletion, or releases it. Returns the updated room, or
+// ErrNotFound when the room does not exist.
+func SetRoomLegalHold(roomID string, hold bool) (*Room, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: "legal_hold", Value: ""}}}}
+	if hold {
+		update = bson.D{{Key: "$set", Value: bson.D{{Key: "legal_hold", Value: true}}}}
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var room Room
+	err = roomCollection.FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: objID}}, update, opts).Decode(&room)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
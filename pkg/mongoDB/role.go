@@ -0,0 +1,125 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Room roles, from least to most privileged. The owner is the room's
+// CreatedBy; moderators are listed in Room.Roles; every other member is a
+// plain member.
+const (
+	RoomRoleMember    = "member"
+	RoomRoleModerator = "moderator"
+	RoomRoleOwner     = "owner"
+)
+
+// RoleOf returns username's role in the room, or "" if they are not a member.
+func (r Room) RoleOf(username string) string {
+	if username == "" {
+		return ""
+	}
+	if r.CreatedBy == username {
+		return RoomRoleOwner
+	}
+	isMember := false
+	for _, m := range r.Members {
+		if m == username {
+			isMember = true
+			break
+		}
+	}
+	if !isMember {
+		return ""
+	}
+	if r.Roles[username] == RoomRoleModerator {
+		return RoomRoleModerator
+	}
+	return RoomRoleMember
+}
+
+// Moderators returns the owner and every moderator of the room.
+func (r Room) Moderators() []string {
+	mods := []string{}
+	if r.CreatedBy != "" {
+		mods = append(mods, r.CreatedBy)
+	}
+	for _, m := range r.Members {
+		if m != r.CreatedBy && r.Roles[m] == RoomRoleModerator {
+			mods = append(mods, m)
+		}
+	}
+	return mods
+}
+
+// SetRoomRole makes a current member of roomID a moderator or a plain member.
+// The owner's role cannot be changed this way; see TransferRoomOwnership.
+// Returns ErrNotFound when username is not a non-owner member.
+func SetRoomRole(roomID, username, role string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: objID},
+		{Key: "members", Value: username},
+		{Key: "created_by", Value: bson.D{{Key: "$ne", Value: username}}},
+	}
+	var update bson.D
+	if role == RoomRoleModerator {
+		update = bson.D{{Key: "$set", Value: bson.D{{Key: "roles." + username, Value: RoomRoleModerator}}}}
+	} else {
+		update = bson.D{{Key: "$unset", Value: bson.D{{Key: "roles." + username, Value: ""}}}}
+	}
+
+	result, err := roomCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// TransferRoomOwnership hands roomID from its owner from to the member to.
+// The previous owner stays on as a moderator. Returns ErrNotFound when from
+// is no longer the owner or to is not a member.
+func TransferRoomOwnership(roomID, from, to string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: objID},
+		{Key: "created_by", Value: from},
+		{Key: "members", Value: to},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "created_by", Value: to},
+			{Key: "roles." + from, Value: RoomRoleModerator},
+		}},
+		{Key: "$unset", Value: bson.D{{Key: "roles." + to, Value: ""}}},
+	}
+
+	result, err := roomCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
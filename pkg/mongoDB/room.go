@@ -14,7 +14,7 @@ import (
 
 type Room struct {
 	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name        string             `json:"name" bson:"name"`
+	Name        string             `json:"name" bson:"name,omitempty"` // empty for DMs
 	Description string             `json:"description" bson:"description"`
 	IsPublic    bool               `json:"is_public" bson:"is_public"`
 	Password    string             `json:"-" bson:"password,omitempty"`
@@ -23,6 +23,37 @@ type Room struct {
 	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
 	IsDefault   bool               `json:"is_default" bson:"is_default"`
 	Members     []string           `json:"members" bson:"members"`
+	Pins        []RoomPin          `json:"pins,omitempty" bson:"pins,omitempty"`
+	Kind        string             `json:"kind,omitempty" bson:"kind,omitempty"` // "" for named rooms, RoomKindDM for DMs
+	DMKey       string             `json:"-" bson:"dm_key,omitempty"`
+	Roles       map[string]string  `json:"-" bson:"roles,omitempty"` // username -> RoomRoleModerator
+	JoinPolicy  string             `json:"join_policy,omitempty" bson:"join_policy,omitempty"`
+	// SlowModeSeconds is the minimum time between two messages of one member; 0 disables it.
+	SlowModeSeconds int `json:"slow_mode_seconds,omitempty" bson:"slow_mode_seconds,omitempty"`
+	// ReadOnly rooms are broadcast channels: only moderators and Posters may send.
+	ReadOnly bool     `json:"read_only,omitempty" bson:"read_only,omitempty"`
+	Posters  []string `json:"posters,omitempty" bson:"posters,omitempty"`
+	// Archived rooms stay readable but accept no new messages, uploads or members.
+	Archived   bool       `json:"archived,omitempty" bson:"archived,omitempty"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty" bson:"archived_at,omitempty"`
+	ArchivedBy string     `json:"archived_by,omitempty" bson:"archived_by,omitempty"`
+	// RetentionDays and RetentionMaxMessages override the server-wide
+	// retention policy; 0 falls back to it. See EffectiveRetention.
+	RetentionDays        int `json:"retention_days,omitempty" bson:"retention_days,omitempty"`
+	RetentionMaxMessages int `json:"retention_max_messages,omitempty" bson:"retention_max_messages,omitempty"`
+	// MessageTTLSeconds makes every new message self-destruct after this many
+	// seconds; 0 keeps messages. Senders may only pick a shorter timer.
+	MessageTTLSeconds int `json:"message_ttl_seconds,omitempty" bson:"message_ttl_seconds,omitempty"`
+	// LegalHold suspends retention purging and message expiry; only global
+	// admins set it.
+	LegalHold bool `json:"legal_hold,omitempty" bson:"legal_hold,omitempty"`
+	// Messages up to PurgedThroughSeq, created at or before PurgedUntil, were
+	// removed by retention.
+	PurgedThroughSeq int64      `json:"purged_through_seq,omitempty" bson:"purged_through_seq,omitempty"`
+	PurgedUntil      *time.Time `json:"purged_until,omitempty" bson:"purged_until,omitempty"`
+	// Bans mirrors the room's ban sanctions so JoinRoom can refuse banned
+	// users in the same update that adds them.
+	Bans []RoomBan `json:"-" bson:"bans,omitempty"`
 }
 
 var roomCollection *mongo.Collection
@@ -36,12 +67,18 @@ func InitRoomCollection(database *mongo.Database) error {
 	database.CreateCollection(ctx, collection)
 	roomCollection = database.Collection(collection)
 
-	// name 필드에 유니크 인덱스 생성
-	indexModel := mongo.IndexModel{
-		Keys:    bson.D{{Key: "name", Value: 1}},
-		Options: options.Index().SetUnique(true),
+	// name 필드에 유니크 인덱스 생성 (이름이 없는 DM은 제외)
+	if err := ensureRoomNameIndex(ctx); err != nil {
+		return err
 	}
-	if _, err := roomCollection.Indexes().CreateOne(ctx, indexModel); err != nil {
+
+	// dm_key 유니크 인덱스 생성 (같은 참여자 조합의 DM은 하나만 존재)
+	if _, err := roomCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "dm_key", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(
+			bson.D{{Key: "dm_key", Value: bson.D{{Key: "$exists", Value: true}}}},
+		),
+	}); err != nil {
 		return err
 	}
 
@@ -80,6 +117,33 @@ func InitRoomCollection(database *mongo.Database) error {
 	return nil
 }
 
+// roomNameIndex is the unique index on room names. It only covers documents
+// with a name so DMs, which have none, never collide.
+const roomNameIndex = "name_unique"
+
+// ensureRoomNameIndex replaces the original full unique index on name, which
+// would reject every DM after the first, with the partial roomNameIndex.
+func ensureRoomNameIndex(ctx context.Context) error {
+	specs, err := roomCollection.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		if spec.Name == "name_1" {
+			if _, err := roomCollection.Indexes().DropOne(ctx, "name_1"); err != nil {
+				return err
+			}
+		}
+	}
+	_, err = roomCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetName(roomNameIndex).SetUnique(true).SetPartialFilterExpression(
+			bson.D{{Key: "name", Value: bson.D{{Key: "$type", Value: "string"}}}},
+		),
+	})
+	return err
+}
+
 // #194: ensureDefaultRoom now returns an error so InitRoomCollection can handle it.
 func ensureDefaultRoom(ctx context.Context) error {
 	filter := bson.D{{Key: "is_default", Value: true}}
@@ -128,19 +192,27 @@ func CreateRoom(room Room) (*Room, error) {
 }
 
 // FindRooms returns all public rooms, plus private rooms where username is a member.
+// DMs are not part of the room directory; see FindDMs. Archived rooms are
+// left out unless includeArchived is set.
 // #280: include private rooms the user is a member of
 // #285: always returns non-nil slice
-func FindRooms(username string) ([]Room, error) {
+func FindRooms(username string, includeArchived bool) ([]Room, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.D{{Key: "$or", Value: bson.A{
-		bson.D{{Key: "is_public", Value: true}},
-		bson.D{
-			{Key: "is_public", Value: false},
-			{Key: "members", Value: username},
-		},
-	}}}
+	filter := bson.D{
+		{Key: "kind", Value: bson.D{{Key: "$ne", Value: RoomKindDM}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "is_public", Value: true}},
+			bson.D{
+				{Key: "is_public", Value: false},
+				{Key: "members", Value: username},
+			},
+		}},
+	}
+	if !includeArchived {
+		filter = append(filter, bson.E{Key: "archived", Value: bson.D{{Key: "$ne", Value: true}}})
+	}
 	cur, err := roomCollection.Find(ctx, filter)
 	if err != nil {
 		return []Room{}, err
@@ -158,6 +230,33 @@ func FindRooms(username string) ([]Room, error) {
 	return rooms, nil
 }
 
+// FindRoomsByIDs returns the rooms with the given IDs; IDs that are invalid
+// or match no room are left out.
+func FindRoomsByIDs(ids []string) ([]Room, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+	rooms := []Room{}
+	if len(objIDs) == 0 {
+		return rooms, nil
+	}
+	cur, err := roomCollection.Find(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: objIDs}}}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	if err := cur.All(ctx, &rooms); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
 func FindRoomByID(id string) (*Room, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -188,9 +287,9 @@ func FindDefaultRoom() (*Room, error) {
 	return &room, nil
 }
 
-// DeleteRoom deletes a room by ID if the username is the creator.
+// DeleteRoom deletes a room by ID. Callers check the caller's room permission first.
 // #187: also deletes related chat messages and file metadata.
-func DeleteRoom(id string, username string) error {
+func DeleteRoom(id string) error {
 	room, err := FindRoomByID(id)
 	if err != nil {
 		return ErrNotFound
@@ -198,8 +297,8 @@ func DeleteRoom(id string, username string) error {
 	if room.IsDefault {
 		return errors.New("기본 채팅방은 삭제할 수 없습니다")
 	}
-	if room.CreatedBy != username {
-		return ErrForbidden
+	if room.LegalHold {
+		return ErrLegalHold
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -219,10 +318,21 @@ func DeleteRoom(id string, username string) error {
 	if fileCollection != nil {
 		fileCollection.DeleteMany(ctx, bson.D{{Key: "room_id", Value: id}})
 	}
+	deleteRoomRecords(ctx, id)
 
 	return nil
 }
 
+// deleteRoomRecords removes the records kept per room outside the rooms,
+// chats and files collections.
+func deleteRoomRecords(ctx context.Context, roomID string) {
+	for _, coll := range []*mongo.Collection{readMarkerCollection, mentionCollection, inviteCollection, joinRequestCollection, sanctionCollection, roomPrefsCollection, scheduledCollection} {
+		if coll != nil {
+			coll.DeleteMany(ctx, bson.D{{Key: "room_id", Value: roomID}})
+		}
+	}
+}
+
 func JoinRoom(roomID string, username string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -232,6 +342,15 @@ func JoinRoom(roomID string, username string) error {
 		return err
 	}
 
+	// 방에서 차단된 사용자는 참여할 수 없음
+	ban, err := FindActiveSanction(roomID, username, SanctionBan)
+	if err != nil {
+		return err
+	}
+	if ban != nil {
+		return ErrBanned
+	}
+
 	// 이미 멤버인 경우 필터: 멤버에 포함되어 있으면 바로 성공
 	filterAlready := bson.D{
 		{Key: "_id", Value: objID},
@@ -246,8 +365,14 @@ func JoinRoom(roomID string, username string) error {
 	}
 
 	// 원자적 업데이트: max_members == 0 (무제한) 이거나 현재 멤버 수가 max_members 미만일 때만 추가
+	// 그 사이에 차단된 사용자도 같은 업데이트에서 거부
 	filter := bson.D{
 		{Key: "_id", Value: objID},
+		{Key: "archived", Value: bson.D{{Key: "$ne", Value: true}}},
+		{Key: "bans", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+			{Key: "username", Value: username},
+			activeSanctionFilter(time.Now()),
+		}}}}}},
 		{Key: "$or", Value: bson.A{
 			bson.D{{Key: "max_members", Value: 0}},
 			bson.D{{Key: "$expr", Value: bson.D{
@@ -265,6 +390,14 @@ func JoinRoom(roomID string, username string) error {
 		return err
 	}
 	if result.MatchedCount == 0 {
+		if room, err := FindRoomByID(roomID); err == nil {
+			if room.Archived {
+				return ErrRoomArchived
+			}
+			if room.HasActiveBan(username, time.Now()) {
+				return ErrBanned
+			}
+		}
 		return errors.New("채팅방 인원이 가득 찼습니다")
 	}
 	return nil
@@ -306,40 +439,56 @@ func LeaveRoom(roomID string, username string) error {
 		return err
 	}
 
-	// Remove the user from members
+	// Remove the user from members, dropping any moderator role
 	_, err = roomCollection.UpdateOne(ctx,
 		bson.D{{Key: "_id", Value: objID}},
-		bson.D{{Key: "$pull", Value: bson.D{{Key: "members", Value: username}}}},
+		bson.D{
+			{Key: "$pull", Value: bson.D{{Key: "members", Value: username}}},
+			{Key: "$unset", Value: bson.D{{Key: "roles." + username, Value: ""}}},
+		},
 	)
 	if err != nil {
 		return err
 	}
+	deleteMemberRoomPrefs(ctx, roomID, username)
 
 	// #186: ownership transfer if creator is leaving
 	if room.CreatedBy == username && !room.IsDefault {
-		// Find remaining members after removal
+		// Find remaining members after removal, moderators first
 		remainingMembers := []string{}
 		for _, m := range room.Members {
-			if m != username {
+			if m != username && room.Roles[m] == RoomRoleModerator {
+				remainingMembers = append(remainingMembers, m)
+			}
+		}
+		for _, m := range room.Members {
+			if m != username && room.Roles[m] != RoomRoleModerator {
 				remainingMembers = append(remainingMembers, m)
 			}
 		}
 
 		if len(remainingMembers) == 0 {
-			// No members left: delete the room and its messages
-			roomCollection.DeleteOne(ctx, bson.D{{Key: "_id", Value: objID}})
-			if chatCollection != nil {
-				chatCollection.DeleteMany(ctx, bson.D{{Key: "room_id", Value: roomID}})
-			}
-			if fileCollection != nil {
-				fileCollection.DeleteMany(ctx, bson.D{{Key: "room_id", Value: roomID}})
+			// No members left: delete the room and its messages. A room under
+			// legal hold is kept, empty, until the hold is released.
+			if !room.LegalHold {
+				roomCollection.DeleteOne(ctx, bson.D{{Key: "_id", Value: objID}})
+				if chatCollection != nil {
+					chatCollection.DeleteMany(ctx, bson.D{{Key: "room_id", Value: roomID}})
+				}
+				if fileCollection != nil {
+					fileCollection.DeleteMany(ctx, bson.D{{Key: "room_id", Value: roomID}})
+				}
+				deleteRoomRecords(ctx, roomID)
 			}
 		} else {
 			// Transfer ownership to the next member
 			newOwner := remainingMembers[0]
 			roomCollection.UpdateOne(ctx,
 				bson.D{{Key: "_id", Value: objID}},
-				bson.D{{Key: "$set", Value: bson.D{{Key: "created_by", Value: newOwner}}}},
+				bson.D{
+					{Key: "$set", Value: bson.D{{Key: "created_by", Value: newOwner}}},
+					{Key: "$unset", Value: bson.D{{Key: "roles." + newOwner, Value: ""}}},
+				},
 			)
 		}
 	}
@@ -397,6 +546,9 @@ func AdminDeleteRoom(roomID string) error {
 	if room.IsDefault {
 		return errors.New("기본 채팅방은 삭제할 수 없습니다")
 	}
+	if room.LegalHold {
+		return ErrLegalHold
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -414,6 +566,7 @@ func AdminDeleteRoom(roomID string) error {
 	if fileCollection != nil {
 		fileCollection.DeleteMany(ctx, bson.D{{Key: "room_id", Value: roomID}})
 	}
+	deleteRoomRecords(ctx, roomID)
 
 	return nil
 }
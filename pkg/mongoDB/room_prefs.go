@@ -0,0 +1,167 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RoomPrefs is how one user organizes one room in their room list. It is kept
+// server-side so the layout is the same on every device.
+type RoomPrefs struct {
+	ID        primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	Username  string             `json:"-" bson:"username"`
+	RoomID    string             `json:"room_id" bson:"room_id"`
+	Favorite  bool               `json:"favorite" bson:"favorite,omitempty"`
+	Folder    string             `json:"folder,omitempty" bson:"folder,omitempty"`
+	SortOrder int                `json:"sort_order,omitempty" bson:"sort_order,omitempty"` // 0 = unordered
+	Hidden    bool               `json:"hidden" bson:"hidden,omitempty"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// RoomPrefsUpdate lists preferences to change. Nil fields are left as they are.
+type RoomPrefsUpdate struct {
+	Favorite  *bool
+	Folder    *string // "" removes the room from its folder
+	SortOrder *int    // 0 clears the custom position
+	Hidden    *bool
+}
+
+var roomPrefsCollection *mongo.Collection
+
+// InitRoomPrefsCollection initializes the room_prefs collection with a unique (username, room_id) index.
+func InitRoomPrefsCollection(database *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := "room_prefs"
+	database.CreateCollection(ctx, collection)
+	roomPrefsCollection = database.Collection(collection)
+
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "username", Value: 1},
+			{Key: "room_id", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := roomPrefsCollection.Indexes().CreateOne(ctx, indexModel)
+	return err
+}
+
+// SetRoomPrefs applies u to username's preferences for roomID and returns them.
+func SetRoomPrefs(username, roomID string, u RoomPrefsUpdate) (*RoomPrefs, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	set := bson.D{{Key: "updated_at", Value: time.Now()}}
+	unset := bson.D{}
+	setOrUnset := func(key string, value interface{}, clear bool) {
+		if clear {
+			unset = append(unset, bson.E{Key: key, Value: ""})
+		} else {
+			set = append(set, bson.E{Key: key, Value: value})
+		}
+	}
+	if u.Favorite != nil {
+		setOrUnset("favorite", true, !*u.Favorite)
+	}
+	if u.Folder != nil {
+		setOrUnset("folder", *u.Folder, *u.Folder == "")
+	}
+	if u.SortOrder != nil {
+		setOrUnset("sort_order", *u.SortOrder, *u.SortOrder == 0)
+	}
+	if u.Hidden != nil {
+		setOrUnset("hidden", true, !*u.Hidden)
+	}
+
+	update := bson.D{{Key: "$set", Value: set}}
+	if len(unset) > 0 {
+		update = append(update, bson.E{Key: "$unset", Value: unset})
+	}
+	filter := bson.D{
+		{Key: "username", Value: username},
+		{Key: "room_id", Value: roomID},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var prefs RoomPrefs
+	if err := roomPrefsCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&prefs); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// SetRoomOrder makes roomIDs username's custom room order, first to last.
+// Rooms not listed lose their custom position.
+func SetRoomOrder(username string, roomIDs []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if roomIDs == nil {
+		roomIDs = []string{}
+	}
+	now := time.Now()
+	models := make([]mongo.WriteModel, 0, len(roomIDs)+1)
+	models = append(models, mongo.NewUpdateManyModel().
+		SetFilter(bson.D{
+			{Key: "username", Value: username},
+			{Key: "room_id", Value: bson.D{{Key: "$nin", Value: roomIDs}}},
+			{Key: "sort_order", Value: bson.D{{Key: "$exists", Value: true}}},
+		}).
+		SetUpdate(bson.D{
+			{Key: "$unset", Value: bson.D{{Key: "sort_order", Value: ""}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: now}}},
+		}))
+	for i, roomID := range roomIDs {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{
+				{Key: "username", Value: username},
+				{Key: "room_id", Value: roomID},
+			}).
+			SetUpdate(bson.D{{Key: "$set", Value: bson.D{
+				{Key: "sort_order", Value: i + 1},
+				{Key: "updated_at", Value: now},
+			}}}).
+			SetUpsert(true))
+	}
+	_, err := roomPrefsCollection.BulkWrite(ctx, models)
+	return err
+}
+
+// deleteMemberRoomPrefs drops username's preferences for roomID once they are
+// no longer a member, so a room they leave does not linger in their order.
+func deleteMemberRoomPrefs(ctx context.Context, roomID, username string) {
+	if roomPrefsCollection != nil {
+		roomPrefsCollection.DeleteOne(ctx, bson.D{
+			{Key: "username", Value: username},
+			{Key: "room_id", Value: roomID},
+		})
+	}
+}
+
+// FindRoomPrefs returns username's room preferences keyed by room ID.
+func FindRoomPrefs(username string) (map[string]RoomPrefs, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cur, err := roomPrefsCollection.Find(ctx, bson.D{{Key: "username", Value: username}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	prefs := make(map[string]RoomPrefs)
+	for cur.Next(ctx) {
+		var p RoomPrefs
+		if err := cur.Decode(&p); err != nil {
+			return nil, err
+		}
+		prefs[p.RoomID] = p
+	}
+	return prefs, cur.Err()
+}
@@ -0,0 +1,139 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RoomSettingsUpdate lists room settings to change. Nil fields are left as they are.
+type RoomSettingsUpdate struct {
+	Name        *string
+	Description *string
+	Password    *string // bcrypt hash; "" removes the password
+	MaxMembers  *int
+	IsPublic    *bool
+	JoinPolicy  *string
+	SlowMode    *int // seconds; 0 turns slow mode off
+	ReadOnly    *bool
+	Posters     *[]string // replaces the posting allow-list; empty clears it
+	// Retention overrides; 0 falls back to the server-wide policy.
+	RetentionDays        *int
+	RetentionMaxMessages *int
+	MessageTTL           *int // seconds; 0 turns the room's self-destruct timer off
+}
+
+// UpdateRoomSettings applies u to roomID and returns the updated room.
+// Returns ErrDuplicateRoomName when the new name is taken and
+// ErrMaxMembersTooLow when the room already has more members than MaxMembers.
+func UpdateRoomSettings(roomID string, u RoomSettingsUpdate) (*Room, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	set := bson.D{}
+	unset := bson.D{}
+	if u.Name != nil {
+		set = append(set, bson.E{Key: "name", Value: *u.Name})
+	}
+	if u.Description != nil {
+		set = append(set, bson.E{Key: "description", Value: *u.Description})
+	}
+	if u.Password != nil {
+		if *u.Password == "" {
+			unset = append(unset, bson.E{Key: "password", Value: ""})
+		} else {
+			set = append(set, bson.E{Key: "password", Value: *u.Password})
+		}
+	}
+	if u.MaxMembers != nil {
+		set = append(set, bson.E{Key: "max_members", Value: *u.MaxMembers})
+	}
+	if u.IsPublic != nil {
+		set = append(set, bson.E{Key: "is_public", Value: *u.IsPublic})
+	}
+	if u.JoinPolicy != nil {
+		set = append(set, bson.E{Key: "join_policy", Value: *u.JoinPolicy})
+	}
+	if u.ReadOnly != nil {
+		set = append(set, bson.E{Key: "read_only", Value: *u.ReadOnly})
+	}
+	if u.Posters != nil {
+		if len(*u.Posters) == 0 {
+			unset = append(unset, bson.E{Key: "posters", Value: ""})
+		} else {
+			set = append(set, bson.E{Key: "posters", Value: *u.Posters})
+		}
+	}
+	if u.SlowMode != nil {
+		if *u.SlowMode == 0 {
+			unset = append(unset, bson.E{Key: "slow_mode_seconds", Value: ""})
+		} else {
+			set = append(set, bson.E{Key: "slow_mode_seconds", Value: *u.SlowMode})
+		}
+	}
+	if u.RetentionDays != nil {
+		if *u.RetentionDays == 0 {
+			unset = append(unset, bson.E{Key: "retention_days", Value: ""})
+		} else {
+			set = append(set, bson.E{Key: "retention_days", Value: *u.RetentionDays})
+		}
+	}
+	if u.RetentionMaxMessages != nil {
+		if *u.RetentionMaxMessages == 0 {
+			unset = append(unset, bson.E{Key: "retention_max_messages", Value: ""})
+		} else {
+			set = append(set, bson.E{Key: "retention_max_messages", Value: *u.RetentionMaxMessages})
+		}
+	}
+	if u.MessageTTL != nil {
+		if *u.MessageTTL == 0 {
+			unset = append(unset, bson.E{Key: "message_ttl_seconds", Value: ""})
+		} else {
+			set = append(set, bson.E{Key: "message_ttl_seconds", Value: *u.MessageTTL})
+		}
+	}
+
+	filter := bson.D{{Key: "_id", Value: objID}}
+	if u.MaxMembers != nil && *u.MaxMembers > 0 {
+		// Never shrink below the current member count.
+		filter = append(filter, bson.E{Key: "$expr", Value: bson.D{
+			{Key: "$lte", Value: bson.A{bson.D{{Key: "$size", Value: "$members"}}, *u.MaxMembers}},
+		}})
+	}
+	update := bson.D{}
+	if len(set) > 0 {
+		update = append(update, bson.E{Key: "$set", Value: set})
+	}
+	if len(unset) > 0 {
+		update = append(update, bson.E{Key: "$unset", Value: unset})
+	}
+	if len(update) == 0 {
+		return FindRoomByID(roomID)
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var room Room
+	err = roomCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&room)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrDuplicateRoomName
+	}
+	if err == mongo.ErrNoDocuments {
+		if _, findErr := FindRoomByID(roomID); findErr == nil {
+			return nil, ErrMaxMembersTooLow
+		}
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
@@ -13,6 +13,7 @@ package mongodb
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -94,3 +95,55 @@ func TestCheckRoomPassword_EmptyHash(t *testing.T) {
 		t.Error("CheckRoomPassword should return true when the room has no password set (empty input)")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// HasActiveBan
+// ---------------------------------------------------------------------------
+
+func TestRoomHasActiveBan(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+	room := Room{Bans: []RoomBan{
+		{Username: "forever"},
+		{Username: "expired", ExpiresAt: &past},
+		{Username: "pending", ExpiresAt: &future},
+	}}
+
+	if !room.HasActiveBan("forever", now) {
+		t.Error("expected a ban without expiry to be active")
+	}
+	if room.HasActiveBan("expired", now) {
+		t.Error("expected an expired ban to be inactive")
+	}
+	if !room.HasActiveBan("pending", now) {
+		t.Error("expected an unexpired ban to be active")
+	}
+	if room.HasActiveBan("alice", now) {
+		t.Error("expected no ban for an unlisted user")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// EffectiveRetention
+// ---------------------------------------------------------------------------
+
+func TestRoomEffectiveRetention(t *testing.T) {
+	def := RetentionPolicy{Days: 90, MaxMessages: 10000}
+
+	if got := (Room{}).EffectiveRetention(def); got != def {
+		t.Errorf("expected the server default, got %+v", got)
+	}
+	if got := (Room{RetentionDays: 7}).EffectiveRetention(def); got != (RetentionPolicy{Days: 7, MaxMessages: 10000}) {
+		t.Errorf("expected the room's days to override the default, got %+v", got)
+	}
+	if got := (Room{RetentionMaxMessages: 500}).EffectiveRetention(def); got != (RetentionPolicy{Days: 90, MaxMessages: 500}) {
+		t.Errorf("expected the room's message cap to override the default, got %+v", got)
+	}
+	if got := (Room{RetentionDays: 7, LegalHold: true}).EffectiveRetention(def); !got.IsZero() {
+		t.Errorf("expected a legal hold to keep everything, got %+v", got)
+	}
+	if got := (Room{}).EffectiveRetention(RetentionPolicy{}); !got.IsZero() {
+		t.Errorf("expected no policy without a default, got %+v", got)
+	}
+}
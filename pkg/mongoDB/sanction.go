@@ -0,0 +1,248 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Kinds of room sanction.
+const (
+	SanctionBan  = "ban"  // removed from the room and barred from rejoining
+	SanctionMute = "mute" // may stay and read but not send messages
+)
+
+// RoomSanction is a ban or mute of one user in one room. A nil ExpiresAt
+// never expires. Expiry is checked on every lookup, so it holds across
+// restarts; a TTL index removes expired sanctions afterwards.
+type RoomSanction struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	RoomID    string             `json:"room_id" bson:"room_id"`
+	Username  string             `json:"username" bson:"username"`
+	Kind      string             `json:"kind" bson:"kind"`
+	Reason    string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	CreatedBy string             `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	ExpiresAt *time.Time         `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+}
+
+// RoomBan is the copy of a ban sanction kept on the room document.
+type RoomBan struct {
+	Username  string     `bson:"username"`
+	ExpiresAt *time.Time `bson:"expires_at,omitempty"`
+}
+
+// HasActiveBan reports whether username is banned from r at now.
+func (r Room) HasActiveBan(username string, now time.Time) bool {
+	for _, b := range r.Bans {
+		if b.Username == username && (b.ExpiresAt == nil || b.ExpiresAt.After(now)) {
+			return true
+		}
+	}
+	return false
+}
+
+var sanctionCollection *mongo.Collection
+
+// InitSanctionCollection initializes the room_sanctions collection.
+func InitSanctionCollection(database *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := "room_sanctions"
+	database.CreateCollection(ctx, collection)
+	sanctionCollection = database.Collection(collection)
+
+	_, err := sanctionCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "room_id", Value: 1},
+				{Key: "username", Value: 1},
+				{Key: "kind", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// activeSanctionFilter matches sanctions that have not expired at now.
+func activeSanctionFilter(now time.Time) bson.E {
+	return bson.E{Key: "$or", Value: bson.A{
+		bson.D{{Key: "expires_at", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: now}}}},
+	}}
+}
+
+// SetRoomSanction creates or replaces username's sanction of the given kind in roomID.
+func SetRoomSanction(s RoomSanction) (*RoomSanction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s.CreatedAt = time.Now()
+	filter := bson.D{
+		{Key: "room_id", Value: s.RoomID},
+		{Key: "username", Value: s.Username},
+		{Key: "kind", Value: s.Kind},
+	}
+	set := bson.D{
+		{Key: "reason", Value: s.Reason},
+		{Key: "created_by", Value: s.CreatedBy},
+		{Key: "created_at", Value: s.CreatedAt},
+	}
+	update := bson.D{}
+	if s.ExpiresAt != nil {
+		set = append(set, bson.E{Key: "expires_at", Value: *s.ExpiresAt})
+	} else {
+		update = append(update, bson.E{Key: "$unset", Value: bson.D{{Key: "expires_at", Value: ""}}})
+	}
+	update = append(update, bson.E{Key: "$set", Value: set})
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var saved RoomSanction
+	if err := sanctionCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&saved); err != nil {
+		return nil, err
+	}
+	if s.Kind == SanctionBan {
+		if err := setRoomBan(ctx, s.RoomID, RoomBan{Username: s.Username, ExpiresAt: s.ExpiresAt}); err != nil {
+			return nil, err
+		}
+	}
+	return &saved, nil
+}
+
+// setRoomBan replaces ban.Username's entry in the room's bans in one update.
+func setRoomBan(ctx context.Context, roomID string, ban RoomBan) error {
+	objID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return err
+	}
+	entry := bson.D{{Key: "username", Value: bson.D{{Key: "$literal", Value: ban.Username}}}}
+	if ban.ExpiresAt != nil {
+		entry = append(entry, bson.E{Key: "expires_at", Value: *ban.ExpiresAt})
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{{Key: "bans", Value: bson.D{{Key: "$concatArrays", Value: bson.A{
+			bson.D{{Key: "$filter", Value: bson.D{
+				{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$bans", bson.A{}}}}},
+				{Key: "cond", Value: bson.D{{Key: "$ne", Value: bson.A{"$$this.username", bson.D{{Key: "$literal", Value: ban.Username}}}}}},
+			}}},
+			bson.A{entry},
+		}}}}}}},
+	}
+	_, err = roomCollection.UpdateOne(ctx, bson.D{{Key: "_id", Value: objID}}, pipeline)
+	return err
+}
+
+// RemoveRoomSanction lifts username's sanction of kind in roomID.
+// Returns ErrNotFound if there was none.
+func RemoveRoomSanction(roomID, username, kind string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := sanctionCollection.DeleteOne(ctx, bson.D{
+		{Key: "room_id", Value: roomID},
+		{Key: "username", Value: username},
+		{Key: "kind", Value: kind},
+		activeSanctionFilter(time.Now()),
+	})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	if kind == SanctionBan {
+		objID, err := primitive.ObjectIDFromHex(roomID)
+		if err != nil {
+			return err
+		}
+		_, err = roomCollection.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: objID}},
+			bson.D{{Key: "$pull", Value: bson.D{{Key: "bans", Value: bson.D{{Key: "username", Value: username}}}}}},
+		)
+		return err
+	}
+	return nil
+}
+
+// FindActiveSanction returns username's unexpired sanction of kind in roomID,
+// or nil if there is none.
+func FindActiveSanction(roomID, username, kind string) (*RoomSanction, error) {
+	if sanctionCollection == nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "room_id", Value: roomID},
+		{Key: "username", Value: username},
+		{Key: "kind", Value: kind},
+		activeSanctionFilter(time.Now()),
+	}
+	var s RoomSanction
+	err := sanctionCollection.FindOne(ctx, filter).Decode(&s)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// FindRoomSanctions returns roomID's unexpired bans and mutes, newest first.
+func FindRoomSanctions(roomID string) ([]RoomSanction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "room_id", Value: roomID},
+		activeSanctionFilter(time.Now()),
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cur, err := sanctionCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	sanctions := []RoomSanction{}
+	if err := cur.All(ctx, &sanctions); err != nil {
+		return nil, err
+	}
+	return sanctions, nil
+}
+
+// RemoveRoomMember drops username from roomID's members along with any room
+// role and their preferences for the room.
+func RemoveRoomMember(roomID, username string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return err
+	}
+	_, err = roomCollection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: objID}},
+		bson.D{
+			{Key: "$pull", Value: bson.D{{Key: "members", Value: username}}},
+			{Key: "$unset", Value: bson.D{{Key: "roles." + username, Value: ""}}},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	deleteMemberRoomPrefs(ctx, roomID, username)
+	return nil
+}
@@ -0,0 +1,238 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Scheduled message states.
+const (
+	ScheduledPending = "pending" // waiting for send_at
+	ScheduledSending = "sending" // claimed by a server that is posting it
+	ScheduledSent    = "sent"
+	ScheduledSkipped = "skipped" // could not be posted, see SkipReason
+)
+
+// ScheduledMessage is a message written now and posted to its room at SendAt.
+// Once posted, the chat message has the same ID, so a retried send can never
+// store it twice.
+type ScheduledMessage struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	RoomID     string             `json:"room_id" bson:"room_id"`
+	Username   string             `json:"username" bson:"username"`
+	Message    string             `json:"message" bson:"message"`
+	SendAt     time.Time          `json:"send_at" bson:"send_at"`
+	Status     string             `json:"status" bson:"status"`
+	SkipReason string             `json:"skip_reason,omitempty" bson:"skip_reason,omitempty"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt  *time.Time         `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+	ClaimedAt  *time.Time         `json:"-" bson:"claimed_at,omitempty"`
+	SentAt     *time.Time         `json:"sent_at,omitempty" bson:"sent_at,omitempty"`
+}
+
+// ScheduledMessageUpdate lists changes to a pending scheduled message. Nil
+// fields are left as they are.
+type ScheduledMessageUpdate struct {
+	Message *string
+	SendAt  *time.Time
+}
+
+var scheduledCollection *mongo.Collection
+
+// InitScheduledCollection initializes the scheduled_messages collection.
+func InitScheduledCollection(database *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := "scheduled_messages"
+	database.CreateCollection(ctx, collection)
+	scheduledCollection = database.Collection(collection)
+
+	_, err := scheduledCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "send_at", Value: 1}}},
+		{Keys: bson.D{{Key: "room_id", Value: 1}, {Key: "username", Value: 1}, {Key: "send_at", Value: 1}}},
+	})
+	return err
+}
+
+// CreateScheduledMessage stores msg as pending and returns the saved record.
+func CreateScheduledMessage(msg ScheduledMessage) (*ScheduledMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	msg.Status = ScheduledPending
+	msg.CreatedAt = time.Now()
+	result, err := scheduledCollection.InsertOne(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.ID = result.InsertedID.(primitive.ObjectID)
+	return &msg, nil
+}
+
+// CountPendingScheduledMessages returns how many messages username has
+// waiting to be posted in roomID.
+func CountPendingScheduledMessages(roomID, username string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return scheduledCollection.CountDocuments(ctx, bson.D{
+		{Key: "room_id", Value: roomID},
+		{Key: "username", Value: username},
+		{Key: "status", Value: ScheduledPending},
+	})
+}
+
+// FindScheduledMessages returns username's scheduled messages in roomID in
+// send order: only pending ones unless all is set.
+func FindScheduledMessages(roomID, username string, all bool) ([]ScheduledMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "room_id", Value: roomID},
+		{Key: "username", Value: username},
+	}
+	if !all {
+		filter = append(filter, bson.E{Key: "status", Value: ScheduledPending})
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "send_at", Value: 1}}).SetLimit(100)
+	cur, err := scheduledCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	messages := []ScheduledMessage{}
+	if err := cur.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// pendingScheduledFilter matches username's pending scheduled message id in roomID.
+func pendingScheduledFilter(id, roomID, username string) (bson.D, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	return bson.D{
+		{Key: "_id", Value: oid},
+		{Key: "room_id", Value: roomID},
+		{Key: "username", Value: username},
+		{Key: "status", Value: ScheduledPending},
+	}, nil
+}
+
+// UpdateScheduledMessage applies u to username's pending scheduled message id
+// in roomID. Returns ErrNotFound if there is no such message or it has
+// already been posted.
+func UpdateScheduledMessage(id, roomID, username string, u ScheduledMessageUpdate) (*ScheduledMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter, err := pendingScheduledFilter(id, roomID, username)
+	if err != nil {
+		return nil, err
+	}
+	set := bson.D{{Key: "updated_at", Value: time.Now()}}
+	if u.Message != nil {
+		set = append(set, bson.E{Key: "message", Value: *u.Message})
+	}
+	if u.SendAt != nil {
+		set = append(set, bson.E{Key: "send_at", Value: *u.SendAt})
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var msg ScheduledMessage
+	err = scheduledCollection.FindOneAndUpdate(ctx, filter, bson.D{{Key: "$set", Value: set}}, opts).Decode(&msg)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// CancelScheduledMessage deletes username's pending scheduled message id in
+// roomID. Returns ErrNotFound if there is no such message or it has already
+// been posted.
+func CancelScheduledMessage(id, roomID, username string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter, err := pendingScheduledFilter(id, roomID, username)
+	if err != nil {
+		return err
+	}
+	result, err := scheduledCollection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ClaimDueScheduledMessage atomically claims one message due at now, so
+// exactly one server posts it. A claim older than staleBefore is taken over,
+// in case the server holding it died mid-send. Returns nil when nothing is due.
+func ClaimDueScheduledMessage(now, staleBefore time.Time) (*ScheduledMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{
+			{Key: "status", Value: ScheduledPending},
+			{Key: "send_at", Value: bson.D{{Key: "$lte", Value: now}}},
+		},
+		bson.D{
+			{Key: "status", Value: ScheduledSending},
+			{Key: "claimed_at", Value: bson.D{{Key: "$lte", Value: staleBefore}}},
+		},
+	}}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: ScheduledSending},
+		{Key: "claimed_at", Value: now},
+	}}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "send_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var msg ScheduledMessage
+	err := scheduledCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&msg)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// FinishScheduledMessage records the outcome of a claimed message: status is
+// ScheduledSent or ScheduledSkipped with reason.
+func FinishScheduledMessage(id primitive.ObjectID, status, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	set := bson.D{{Key: "status", Value: status}}
+	if status == ScheduledSent {
+		set = append(set, bson.E{Key: "sent_at", Value: time.Now()})
+	}
+	if reason != "" {
+		set = append(set, bson.E{Key: "skip_reason", Value: reason})
+	}
+	_, err := scheduledCollection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: id}, {Key: "status", Value: ScheduledSending}},
+		bson.D{{Key: "$set", Value: set}},
+	)
+	return err
+}
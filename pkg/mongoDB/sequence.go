@@ -0,0 +1,64 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// roomSequence is the per-room counter document; _id is the room ID.
+type roomSequence struct {
+	RoomID string `bson:"_id"`
+	Seq    int64  `bson:"seq"`
+}
+
+var sequenceCollection *mongo.Collection
+
+// InitSequenceCollection initializes the room_sequences counter collection.
+func InitSequenceCollection(database *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := "room_sequences"
+	database.CreateCollection(ctx, collection)
+	sequenceCollection = database.Collection(collection)
+	return nil
+}
+
+// NextRoomSeq atomically allocates the next sequence number for roomID.
+// Sequence numbers start at 1 and are strictly increasing per room across all
+// server instances; a number is never handed out twice.
+func NextRoomSeq(roomID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{{Key: "_id", Value: roomID}}
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "seq", Value: int64(1)}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var counter roomSequence
+	if err := sequenceCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter); err != nil {
+		return 0, err
+	}
+	return counter.Seq, nil
+}
+
+// CurrentRoomSeq returns the last sequence number allocated for roomID, or 0
+// if none has been allocated yet.
+func CurrentRoomSeq(roomID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var counter roomSequence
+	err := sequenceCollection.FindOne(ctx, bson.D{{Key: "_id", Value: roomID}}).Decode(&counter)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return counter.Seq, nil
+}
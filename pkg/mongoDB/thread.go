@@ -0,0 +1,83 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UpdateThreadSummary atomically adjusts the reply_count of a parent message by delta.
+// A positive delta also advances last_reply_at to replyAt ($max keeps it monotonic).
+// A negative delta never takes reply_count below zero.
+// Returns the updated parent message.
+func UpdateThreadSummary(parentID string, delta int, replyAt time.Time) (*Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	oid, err := primitive.ObjectIDFromHex(parentID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	filter := bson.D{{Key: "_id", Value: oid}}
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "reply_count", Value: delta}}}}
+	if delta > 0 {
+		update = append(update, bson.E{Key: "$max", Value: bson.D{{Key: "last_reply_at", Value: replyAt}}})
+	} else {
+		filter = append(filter, bson.E{Key: "reply_count", Value: bson.D{{Key: "$gte", Value: -delta}}})
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var parent Chat
+	err = chatCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&parent)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &parent, nil
+}
+
+// FindThreadReplies returns up to limit non-deleted replies to parentID in roomID,
+// in ascending time order. When before is non-zero only replies created strictly
+// before it are returned, so clients can page backwards through long threads.
+func FindThreadReplies(roomID, parentID string, before time.Time, limit int64) ([]Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "room_id", Value: roomID},
+		{Key: "reply_to", Value: parentID},
+		notExpired(),
+		{Key: "is_deleted", Value: bson.D{{Key: "$ne", Value: true}}},
+	}
+	if !before.IsZero() {
+		filter = append(filter, bson.E{Key: "created_at", Value: bson.D{{Key: "$lt", Value: before}}})
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+
+	cur, err := chatCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	chats := []Chat{}
+	for cur.Next(ctx) {
+		var chat Chat
+		if err := cur.Decode(&chat); err != nil {
+			return nil, err
+		}
+		chats = append(chats, chat)
+	}
+
+	for i, j := 0, len(chats)-1; i < j; i, j = i+1, j-1 {
+		chats[i], chats[j] = chats[j], chats[i]
+	}
+	return chats, nil
+}
@@ -145,6 +145,37 @@ func GetBatchPublicKeys(usernames []string) (map[string]string, error) {
 	return keys, cursor.Err()
 }
 
+// FindExistingUsernames returns the subset of usernames that belong to active
+// (non-blocked) accounts.
+func FindExistingUsernames(usernames []string) ([]string, error) {
+	if len(usernames) == 0 {
+		return []string{}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "username", Value: bson.D{{Key: "$in", Value: usernames}}},
+		{Key: "role", Value: bson.D{{Key: "$ne", Value: "blocked"}}},
+	}
+	projection := options.Find().SetProjection(bson.D{{Key: "username", Value: 1}})
+
+	cursor, err := userCollection.Find(ctx, filter, projection)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	found := make([]string, 0, len(usernames))
+	for cursor.Next(ctx) {
+		var u User
+		if err := cursor.Decode(&u); err == nil {
+			found = append(found, u.Username)
+		}
+	}
+	return found, cursor.Err()
+}
+
 // UpdateUserProfile updates display_name, status_message, and avatar_url for the given username.
 func UpdateUserProfile(username, displayName, statusMessage, avatarURL string) (*User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
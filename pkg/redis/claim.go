@@ -0,0 +1,41 @@
+package redisclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const claimPrefix = "claim:" // STRING key: claim:{key} (TTL)
+
+// Claim atomically reserves key for ttl using SET NX. It returns true only
+// for the first caller across all server instances until the key expires.
+func Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if client == nil {
+		return false, fmt.Errorf("redis: client not initialized")
+	}
+	return client.SetNX(ctx, claimPrefix+key, "1", ttl).Result()
+}
+
+// ClaimTTL returns how long the claim on key still holds, or 0 if it is not held.
+func ClaimTTL(ctx context.Context, key string) (time.Duration, error) {
+	if client == nil {
+		return 0, fmt.Errorf("redis: client not initialized")
+	}
+	ttl, err := client.PTTL(ctx, claimPrefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// ReleaseClaim removes a claim so the key can be reserved again.
+func ReleaseClaim(ctx context.Context, key string) error {
+	if client == nil {
+		return fmt.Errorf("redis: client not initialized")
+	}
+	return client.Del(ctx, claimPrefix+key).Err()
+}
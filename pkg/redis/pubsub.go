@@ -10,9 +10,14 @@ import (
 	"github.com/woonglife62/woongkie-talkie/pkg/logger"
 )
 
-const channelPrefix = "chat:room:"
+const (
+	channelPrefix  = "chat:room:"
+	userChannel    = "chat:user"    // user-targeted notifications, shared by all instances
+	controlChannel = "chat:control" // instructions for every instance, e.g. kicks
+)
 
 // Broker manages Redis pub/sub subscriptions with automatic fallback and recovery.
+// Subscriptions and handlers are keyed by Redis channel name.
 type Broker struct {
 	client        *redis.Client
 	mu            sync.RWMutex
@@ -44,6 +49,20 @@ func channelName(roomID string) string {
 
 // Publish sends data to the channel for roomID.
 func (b *Broker) Publish(ctx context.Context, roomID string, data []byte) error {
+	return b.publish(ctx, channelName(roomID), data)
+}
+
+// PublishUser sends data to the user notification channel.
+func (b *Broker) PublishUser(ctx context.Context, data []byte) error {
+	return b.publish(ctx, userChannel, data)
+}
+
+// PublishControl sends data to the control channel.
+func (b *Broker) PublishControl(ctx context.Context, data []byte) error {
+	return b.publish(ctx, controlChannel, data)
+}
+
+func (b *Broker) publish(ctx context.Context, channel string, data []byte) error {
 	b.mu.RLock()
 	fb := b.fallback
 	b.mu.RUnlock()
@@ -52,38 +71,52 @@ func (b *Broker) Publish(ctx context.Context, roomID string, data []byte) error
 		return fmt.Errorf("redis: broker in fallback mode, publish skipped")
 	}
 
-	return b.client.Publish(ctx, channelName(roomID), data).Err()
+	return b.client.Publish(ctx, channel, data).Err()
 }
 
 // Subscribe registers handler for messages on roomID and starts a listener goroutine.
 func (b *Broker) Subscribe(roomID string, handler func([]byte)) error {
+	return b.subscribe(channelName(roomID), handler)
+}
+
+// SubscribeUser registers handler for the user notification channel.
+func (b *Broker) SubscribeUser(handler func([]byte)) error {
+	return b.subscribe(userChannel, handler)
+}
+
+// SubscribeControl registers handler for the control channel.
+func (b *Broker) SubscribeControl(handler func([]byte)) error {
+	return b.subscribe(controlChannel, handler)
+}
+
+func (b *Broker) subscribe(channel string, handler func([]byte)) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	if b.fallback || b.client == nil {
-		b.handlers[roomID] = handler
+		b.handlers[channel] = handler
 		return fmt.Errorf("redis: broker in fallback mode, subscription stored but not active")
 	}
 
-	if _, exists := b.subscriptions[roomID]; exists {
-		b.handlers[roomID] = handler
+	if _, exists := b.subscriptions[channel]; exists {
+		b.handlers[channel] = handler
 		return nil
 	}
 
-	ps := b.client.Subscribe(b.ctx, channelName(roomID))
-	b.subscriptions[roomID] = ps
-	b.handlers[roomID] = handler
+	ps := b.client.Subscribe(b.ctx, channel)
+	b.subscriptions[channel] = ps
+	b.handlers[channel] = handler
 
-	go b.listenRoom(roomID, ps)
+	go b.listen(channel, ps)
 	return nil
 }
 
-// listenRoom reads messages from a PubSub and dispatches them to the handler.
-func (b *Broker) listenRoom(roomID string, ps *redis.PubSub) {
+// listen reads messages from a PubSub and dispatches them to the handler.
+func (b *Broker) listen(channel string, ps *redis.PubSub) {
 	ch := ps.Channel()
 	for msg := range ch {
 		b.mu.RLock()
-		handler := b.handlers[roomID]
+		handler := b.handlers[channel]
 		b.mu.RUnlock()
 
 		if handler != nil {
@@ -94,21 +127,23 @@ func (b *Broker) listenRoom(roomID string, ps *redis.PubSub) {
 
 // Unsubscribe removes the subscription for roomID.
 func (b *Broker) Unsubscribe(roomID string) error {
+	channel := channelName(roomID)
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	ps, exists := b.subscriptions[roomID]
+	ps, exists := b.subscriptions[channel]
 	if !exists {
-		delete(b.handlers, roomID)
+		delete(b.handlers, channel)
 		return nil
 	}
 
 	if err := ps.Close(); err != nil {
-		logger.Logger.Warnw("redis: failed to close pubsub", "roomID", roomID, "error", err)
+		logger.Logger.Warnw("redis: failed to close pubsub", "channel", channel, "error", err)
 	}
 
-	delete(b.subscriptions, roomID)
-	delete(b.handlers, roomID)
+	delete(b.subscriptions, channel)
+	delete(b.handlers, channel)
 	return nil
 }
 
@@ -133,16 +168,16 @@ func (b *Broker) Close() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	for roomID, ps := range b.subscriptions {
+	for channel, ps := range b.subscriptions {
 		if err := ps.Close(); err != nil {
-			logger.Logger.Warnw("redis: error closing subscription", "roomID", roomID, "error", err)
+			logger.Logger.Warnw("redis: error closing subscription", "channel", channel, "error", err)
 		}
 	}
 	b.subscriptions = make(map[string]*redis.PubSub)
 }
 
 // monitorConnection periodically pings Redis, switches to fallback on failure,
-// and re-subscribes all rooms when the connection recovers.
+// and re-subscribes all channels when the connection recovers.
 func (b *Broker) monitorConnection() {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
@@ -171,9 +206,9 @@ func (b *Broker) monitorConnection() {
 					logger.Logger.Warnw("redis: connection lost, switching to fallback", "error", err)
 					b.fallback = true
 					// Close all existing subscriptions; they are now stale.
-					for roomID, ps := range b.subscriptions {
+					for channel, ps := range b.subscriptions {
 						ps.Close()
-						delete(b.subscriptions, roomID)
+						delete(b.subscriptions, channel)
 					}
 				}
 			} else if wasDown {
@@ -186,13 +221,13 @@ func (b *Broker) monitorConnection() {
 				}
 				b.mu.Unlock()
 
-				for roomID := range handlers {
-					ps := b.client.Subscribe(b.ctx, channelName(roomID))
+				for channel := range handlers {
+					ps := b.client.Subscribe(b.ctx, channel)
 					b.mu.Lock()
-					b.subscriptions[roomID] = ps
+					b.subscriptions[channel] = ps
 					b.mu.Unlock()
-					go b.listenRoom(roomID, ps)
-					logger.Logger.Infow("redis: re-subscribed room", "roomID", roomID)
+					go b.listen(channel, ps)
+					logger.Logger.Infow("redis: re-subscribed channel", "channel", channel)
 				}
 				continue
 			}
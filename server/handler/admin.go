@@ -8,7 +8,9 @@ import (
 	"strconv"
 
 	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
 	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.uber.org/zap"
 )
 
 // AdminStatsHandler handles GET /admin/stats
@@ -138,6 +140,9 @@ func AdminDeleteRoomHandler(c echo.Context) error {
 		if errors.Is(err, mongodb.ErrNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방을 찾을 수 없습니다"})
 		}
+		if errors.Is(err, mongodb.ErrLegalHold) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": legalHoldDeleteError})
+		}
 		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
 	}
 
@@ -147,6 +152,38 @@ func AdminDeleteRoomHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"message": "채팅방이 삭제되었습니다"})
 }
 
+// AdminLegalHoldHandler handles PUT /admin/rooms/:id/legal-hold
+// A room under legal hold keeps all of its messages and files regardless of
+// retention, and cannot be deleted until the hold is released.
+func AdminLegalHoldHandler(c echo.Context) error {
+	roomID := c.Param("id")
+	if roomID == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "채팅방 ID가 필요합니다"})
+	}
+
+	var req struct {
+		LegalHold bool `json:"legal_hold"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+
+	room, err := mongodb.SetRoomLegalHold(roomID, req.LegalHold)
+	if err != nil {
+		if errors.Is(err, mongodb.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방을 찾을 수 없습니다"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "법적 보존 설정 변경에 실패했습니다"})
+	}
+
+	event := "room_legal_hold_released"
+	if req.LegalHold {
+		event = "room_legal_hold_placed"
+	}
+	logger.AuditLog(event, GetUsername(c), zap.String("room_id", roomID), zap.String("ip", c.RealIP()))
+	return c.JSON(http.StatusOK, room)
+}
+
 // AdminAnnounceHandler handles POST /admin/rooms/:id/announce
 // #220: 2000 char limit on announce message
 // #218: save announce message to MongoDB
@@ -187,7 +224,10 @@ func AdminAnnounceHandler(c echo.Context) error {
 		RoomID:  roomID,
 	}
 
-	// #218: persist announce to MongoDB
+	// #218: persist announce to MongoDB; allocate seq first so the broadcast carries it
+	if seq, err := mongodb.NextRoomSeq(roomID); err == nil {
+		announceMsg.Seq = seq
+	}
 	mongodb.InsertChat(announceMsg)
 
 	hub.Broadcast <- announceMsg
@@ -195,6 +235,54 @@ func AdminAnnounceHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"message": "공지가 전송되었습니다"})
 }
 
+// AdminDeadLettersHandler handles GET /admin/dead-letters?page=1&limit=20
+func AdminDeadLettersHandler(c echo.Context) error {
+	page, _ := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	letters, total, err := mongodb.FindDeadLetters(page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "저장 실패 메시지 목록을 불러올 수 없습니다"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"dead_letters": letters,
+		"total":        total,
+		"page":         page,
+		"limit":        limit,
+	})
+}
+
+// AdminReplayDeadLetterHandler handles POST /admin/dead-letters/:id/replay
+// The message is stored with its original ID, timestamp and seq; clients already
+// received it live, so nothing is broadcast.
+func AdminReplayDeadLetterHandler(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID가 필요합니다"})
+	}
+
+	chat, err := mongodb.ReplayDeadLetter(id)
+	if err != nil {
+		if errors.Is(err, mongodb.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "저장 실패 메시지를 찾을 수 없습니다"})
+		}
+		if errors.Is(err, mongodb.ErrSeqConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "같은 순번의 다른 메시지가 있어 재저장할 수 없습니다"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "메시지 재저장에 실패했습니다"})
+	}
+
+	logger.AuditLog("dead_letter_replayed", GetUsername(c), zap.String("dead_letter_id", id), zap.String("msg_id", chat.ID.Hex()), zap.String("room_id", chat.RoomID))
+	return c.JSON(http.StatusOK, chat)
+}
+
 // AdminDashboardPage handles GET /admin
 func AdminDashboardPage(c echo.Context) error {
 	return c.File(adminViewPath())
@@ -115,3 +115,34 @@ func TestAdminAnnounceHandler_MissingRoomID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
+
+// TestAdminLegalHoldHandler_MissingRoomID verifies that an empty room ID returns 400.
+func TestAdminLegalHoldHandler_MissingRoomID(t *testing.T) {
+	e := newTestEcho()
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/rooms//legal-hold", strings.NewReader(`{"legal_hold":true}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("")
+
+	err := AdminLegalHoldHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// TestAdminReplayDeadLetterHandler_MissingID verifies that an empty ID returns 400.
+func TestAdminReplayDeadLetterHandler_MissingID(t *testing.T) {
+	e := newTestEcho()
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/dead-letters//replay", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("")
+
+	err := AdminReplayDeadLetterHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
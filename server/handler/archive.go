@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.uber.org/zap"
+)
+
+// archivedJoinError is the error shown to anyone joining an archived room.
+const archivedJoinError = "보관된 채팅방에는 참여할 수 없습니다"
+
+// setRoomArchived archives or unarchives the ":id" room and tells everyone in
+// it through ROOM_UPDATE.
+func setRoomArchived(c echo.Context, archived bool) error {
+	room, err := requireRoomPermission(c, roomActionArchiveRoom)
+	if err != nil {
+		return err
+	}
+	if room.IsDefault || room.IsDM() {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "이 채팅방은 보관할 수 없습니다"})
+	}
+	roomID := room.ID.Hex()
+	username := GetUsername(c)
+
+	updated, err := mongodb.SetRoomArchived(roomID, archived, username)
+	if err != nil {
+		if errors.Is(err, mongodb.ErrNotFound) {
+			if archived {
+				return c.JSON(http.StatusConflict, map[string]string{"error": "이미 보관된 채팅방입니다"})
+			}
+			return c.JSON(http.StatusConflict, map[string]string{"error": "보관된 채팅방이 아닙니다"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "채팅방 보관 상태 변경에 실패했습니다"})
+	}
+
+	event := "room_archived"
+	if !archived {
+		event = "room_unarchived"
+	}
+	logger.AuditLog(event, username, zap.String("room_id", roomID), zap.String("ip", c.RealIP()))
+	broadcastRoomUpdate(*updated, username)
+
+	resp := roomToResponse(*updated, RoomMgr.GetOnlineMembers(roomID))
+	resp.MyRole = updated.RoleOf(username)
+	return c.JSON(http.StatusOK, resp)
+}
+
+// POST /rooms/:id/archive
+// History stays readable and searchable; new messages, uploads and joins are rejected.
+func ArchiveRoomHandler(c echo.Context) error {
+	return setRoomArchived(c, true)
+}
+
+// POST /rooms/:id/unarchive
+func UnarchiveRoomHandler(c echo.Context) error {
+	return setRoomArchived(c, false)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestArchiveRoomHandler_Unauthenticated(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/rooms/bad/archive", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("bad")
+
+	err := ArchiveRoomHandler(c)
+	he, ok := err.(*echo.HTTPError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusUnauthorized, he.Code)
+	}
+}
+
+func TestUnarchiveRoomHandler_InvalidRoom(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/rooms/bad/unarchive", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("bad")
+	c.Set("username", "olivia")
+
+	err := UnarchiveRoomHandler(c)
+	he, ok := err.(*echo.HTTPError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusNotFound, he.Code)
+	}
+}
+
+// TestClient_AllowSend_Archived verifies an archived room rejects messages
+// from everyone, including moderators.
+func TestClient_AllowSend_Archived(t *testing.T) {
+	room := &mongodb.Room{ID: primitive.NewObjectID(), Archived: true}
+	c := &Client{
+		Send:           make(chan mongodb.ChatMessage, 1),
+		Username:       "olivia",
+		RoomID:         room.ID.Hex(),
+		room:           room,
+		roomCheckedAt:  time.Now(),
+		slowModeExempt: true,
+	}
+
+	assert.False(t, c.allowSend())
+	warn := <-c.Send
+	assert.Equal(t, "WARN", warn.Event)
+	assert.Equal(t, archivedWarning, warn.Message)
+}
+
+func TestRoomToResponse_Archived(t *testing.T) {
+	at := time.Now()
+	resp := roomToResponse(mongodb.Room{Name: "old", Archived: true, ArchivedAt: &at}, nil)
+	assert.True(t, resp.Archived)
+	assert.Equal(t, &at, resp.ArchivedAt)
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/woonglife62/woongkie-talkie/pkg/config"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	redisclient "github.com/woonglife62/woongkie-talkie/pkg/redis"
+)
+
+// claimStore is the in-memory fallback for Redis claims, used when Redis is
+// unavailable. Claims are only visible to this server instance.
+type claimStore struct {
+	mu        sync.Mutex
+	expires   map[string]time.Time
+	lastSweep time.Time
+}
+
+var localClaims = &claimStore{expires: make(map[string]time.Time)}
+
+// claim reserves key for ttl, returning false if it is already held.
+// Expired entries are swept at most once per minute.
+func (s *claimStore) claim(key string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, exp := range s.expires {
+			if now.After(exp) {
+				delete(s.expires, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if exp, ok := s.expires[key]; ok && now.Before(exp) {
+		return false
+	}
+	s.expires[key] = now.Add(ttl)
+	return true
+}
+
+// remaining returns how long the claim on key still holds, or 0 if it is not held.
+func (s *claimStore) remaining(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if exp, ok := s.expires[key]; ok {
+		if d := time.Until(exp); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func (s *claimStore) release(key string) {
+	s.mu.Lock()
+	delete(s.expires, key)
+	s.mu.Unlock()
+}
+
+// claimOnce reserves key for ttl across all instances via Redis, falling back
+// to the local store when Redis is unavailable or fails.
+func claimOnce(key string, ttl time.Duration) bool {
+	if redisclient.IsAvailable() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ok, err := redisclient.Claim(ctx, key, ttl)
+		if err == nil {
+			return ok
+		}
+		logger.Logger.Warnw("redis claim failed, using local store", "key", key, "error", err)
+	}
+	return localClaims.claim(key, ttl)
+}
+
+// releaseClaim drops a claim taken by claimOnce.
+func releaseClaim(key string) {
+	if redisclient.IsAvailable() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = redisclient.ReleaseClaim(ctx, key)
+	}
+	localClaims.release(key)
+}
+
+// clientMsgIDPattern limits client_msg_id to UUID-like tokens.
+var clientMsgIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// clientMsgKey is the dedup key of a client message ID, scoped per room and user.
+func clientMsgKey(roomID, username, clientMsgID string) string {
+	return "msg:" + roomID + ":" + username + ":" + clientMsgID
+}
+
+// claimClientMsgID reports whether clientMsgID is new for this user and room
+// within config.ClientMsgIDWindow. An empty ID is never deduplicated.
+func claimClientMsgID(roomID, username, clientMsgID string) bool {
+	if clientMsgID == "" {
+		return true
+	}
+	return claimOnce(clientMsgKey(roomID, username, clientMsgID), config.ClientMsgIDWindow)
+}
+
+// releaseClientMsgID lets a client retry clientMsgID after a send failed.
+func releaseClientMsgID(roomID, username, clientMsgID string) {
+	if clientMsgID == "" {
+		return
+	}
+	releaseClaim(clientMsgKey(roomID, username, clientMsgID))
+}
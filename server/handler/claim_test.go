@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestClaimStore_ClaimOnce verifies that a key can only be claimed once until
+// it expires or is released.
+func TestClaimStore_ClaimOnce(t *testing.T) {
+	s := &claimStore{expires: make(map[string]time.Time)}
+
+	assert.True(t, s.claim("k", time.Minute))
+	assert.False(t, s.claim("k", time.Minute), "second claim must fail")
+	assert.True(t, s.claim("other", time.Minute), "keys are independent")
+
+	s.release("k")
+	assert.True(t, s.claim("k", time.Minute), "released key can be claimed again")
+}
+
+// TestClaimStore_Expiry verifies that expired claims can be taken again.
+func TestClaimStore_Expiry(t *testing.T) {
+	s := &claimStore{expires: make(map[string]time.Time)}
+
+	assert.True(t, s.claim("k", 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, s.claim("k", time.Minute))
+}
+
+// TestClaimClientMsgID verifies per-user/room scoping and that an empty ID is
+// never treated as a duplicate.
+func TestClaimClientMsgID(t *testing.T) {
+	assert.True(t, claimClientMsgID("room-a", "alice", ""))
+	assert.True(t, claimClientMsgID("room-a", "alice", ""))
+
+	assert.True(t, claimClientMsgID("room-a", "alice", "c-1"))
+	assert.False(t, claimClientMsgID("room-a", "alice", "c-1"))
+	assert.True(t, claimClientMsgID("room-a", "bob", "c-1"), "other user")
+	assert.True(t, claimClientMsgID("room-b", "alice", "c-1"), "other room")
+
+	releaseClientMsgID("room-a", "alice", "c-1")
+	assert.True(t, claimClientMsgID("room-a", "alice", "c-1"))
+}
+
+// TestClientMsgIDPattern verifies the accepted client_msg_id format.
+func TestClientMsgIDPattern(t *testing.T) {
+	assert.True(t, clientMsgIDPattern.MatchString("550e8400-e29b-41d4-a716-446655440000"))
+	assert.False(t, clientMsgIDPattern.MatchString("a:b"))
+	assert.False(t, clientMsgIDPattern.MatchString(string(make([]byte, 65))))
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+// commandNamePattern limits slash command names.
+var commandNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// Command is a slash command run on the server when a member sends
+// "/<name> <args>" over the WebSocket, before anything is persisted.
+type Command struct {
+	Name        string // without the slash, lower case
+	Usage       string // e.g. "/topic <text>"
+	Description string
+	// Role is the least room role needed to run the command, one of the
+	// mongodb.RoomRole* constants, or "" for anyone in the room.
+	Role string
+	// Posts marks commands that post to the room themselves, so they count
+	// against slow mode too. The other send rules (archive, read-only, mute)
+	// apply to every command.
+	Posts bool
+	Run   func(ctx CommandContext) (CommandResponse, error)
+}
+
+// CommandContext is what a command runs with.
+type CommandContext struct {
+	Room     mongodb.Room
+	Username string
+	Args     string // text after the command name, trimmed
+}
+
+// CommandResponse is a command's answer. An ephemeral response is sent only
+// to the caller; a public one is posted to the room as the caller's message,
+// through the same path as any MSG. An empty Text sends nothing.
+type CommandResponse struct {
+	Text      string
+	Ephemeral bool
+}
+
+// ephemeral and public build command responses.
+func ephemeral(text string) (CommandResponse, error) {
+	return CommandResponse{Text: text, Ephemeral: true}, nil
+}
+
+func public(text string) (CommandResponse, error) {
+	return CommandResponse{Text: text}, nil
+}
+
+// CommandError is a command failure whose text is shown to the caller. Other
+// errors are logged and reported as a generic failure.
+type CommandError string
+
+func (e CommandError) Error() string { return string(e) }
+
+// usageError tells the caller how to use cmd.
+func usageError(cmd Command) error {
+	return CommandError("사용법: " + cmd.Usage)
+}
+
+// commandRegistry holds the slash commands by name.
+type commandRegistry struct {
+	mu       sync.RWMutex
+	commands map[string]Command
+}
+
+var commands = &commandRegistry{commands: make(map[string]Command)}
+
+// RegisterCommand adds a slash command. Bots and plugins call it at startup
+// to extend the built-in commands; a name may only be registered once.
+func RegisterCommand(cmd Command) error {
+	if !commandNamePattern.MatchString(cmd.Name) {
+		return fmt.Errorf("invalid command name %q", cmd.Name)
+	}
+	if cmd.Run == nil {
+		return fmt.Errorf("command %q has no Run", cmd.Name)
+	}
+	if _, ok := roomRoleRank[cmd.Role]; cmd.Role != "" && !ok {
+		return fmt.Errorf("command %q has unknown role %q", cmd.Name, cmd.Role)
+	}
+	if cmd.Usage == "" {
+		cmd.Usage = "/" + cmd.Name
+	}
+
+	commands.mu.Lock()
+	defer commands.mu.Unlock()
+	if _, ok := commands.commands[cmd.Name]; ok {
+		return fmt.Errorf("command %q already registered", cmd.Name)
+	}
+	commands.commands[cmd.Name] = cmd
+	return nil
+}
+
+// lookup returns the command called name.
+func (r *commandRegistry) lookup(name string) (Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cmd, ok := r.commands[name]
+	return cmd, ok
+}
+
+// available returns the commands username may run in room, sorted by name.
+func (r *commandRegistry) available(room mongodb.Room, username string) []Command {
+	r.mu.RLock()
+	list := make([]Command, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		list = append(list, cmd)
+	}
+	r.mu.RUnlock()
+
+	allowed := list[:0]
+	for _, cmd := range list {
+		if roomHasRole(room, username, cmd.Role) {
+			allowed = append(allowed, cmd)
+		}
+	}
+	sort.Slice(allowed, func(i, j int) bool { return allowed[i].Name < allowed[j].Name })
+	return allowed
+}
+
+// parseCommand splits "/name args" into its lower-cased name and trimmed
+// args. ok is false for text that is not a command, including "//" which
+// escapes a message starting with a slash.
+func parseCommand(text string) (name, args string, ok bool) {
+	if !strings.HasPrefix(text, "/") || strings.HasPrefix(text, "//") {
+		return "", "", false
+	}
+	name, args, _ = strings.Cut(text[1:], " ")
+	if name == "" {
+		return "", "", false
+	}
+	return strings.ToLower(name), strings.TrimSpace(args), true
+}
+
+// runCommand handles a slash command sent on this socket. It returns the text
+// to post as a public message, or "" when nothing should be posted, and
+// whether the command ran; failures and ephemeral answers go to the caller
+// only.
+func (c *Client) runCommand(name, args string) (string, bool) {
+	cmd, ok := commands.lookup(name)
+	if !ok {
+		c.sendWarn(fmt.Sprintf("알 수 없는 명령어입니다: /%s (/help로 목록 확인)", html.EscapeString(name)))
+		return "", false
+	}
+	// Commands act with the caller's current role, so the room is re-read
+	// rather than taken from the socket's cache.
+	room := c.refreshRoom()
+	if room == nil {
+		c.sendWarn("채팅방 정보를 불러올 수 없습니다. 잠시 후 다시 시도해주세요.")
+		return "", false
+	}
+	if !roomHasRole(*room, c.Username, cmd.Role) {
+		c.sendWarn(fmt.Sprintf("/%s 명령어를 사용할 권한이 없습니다.", cmd.Name))
+		return "", false
+	}
+	if !c.allowSend() || (cmd.Posts && !c.claimSlowMode()) {
+		return "", false
+	}
+
+	resp, err := cmd.Run(CommandContext{Room: *room, Username: c.Username, Args: args})
+	if err != nil {
+		if cmd.Posts {
+			c.releaseSlowMode()
+		}
+		var cmdErr CommandError
+		if errors.As(err, &cmdErr) {
+			c.sendWarn(html.EscapeString(string(cmdErr)))
+		} else {
+			logger.Logger.Warnw("slash command failed",
+				"command", cmd.Name,
+				"room_id", c.RoomID,
+				"username", c.Username,
+				"error", err,
+			)
+			c.sendWarn(fmt.Sprintf("/%s 명령어를 실행하지 못했습니다.", cmd.Name))
+		}
+		return "", false
+	}
+	if resp.Ephemeral {
+		if resp.Text != "" {
+			c.trySend(mongodb.ChatMessage{
+				User:    "system",
+				Message: html.EscapeString(resp.Text),
+				RoomID:  c.RoomID,
+				Event:   "COMMAND",
+			})
+		}
+		return "", true
+	}
+	if len([]rune(resp.Text)) > 2000 {
+		c.sendWarn("메시지는 2000자를 초과할 수 없습니다.")
+		return "", false
+	}
+	return resp.Text, true
+}
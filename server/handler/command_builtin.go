@@ -0,0 +1,274 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.uber.org/zap"
+)
+
+// shrug is appended by /shrug.
+const shrug = `¯\_(ツ)_/¯`
+
+// commandUserPattern matches the @username argument of a command.
+var commandUserPattern = regexp.MustCompile(`^@?([a-zA-Z0-9_-]{3,30})$`)
+
+func init() {
+	for _, cmd := range []Command{
+		{
+			Name:        "help",
+			Usage:       "/help",
+			Description: "사용할 수 있는 명령어 목록",
+			Run:         runHelp,
+		},
+		{
+			Name:        "me",
+			Usage:       "/me <행동>",
+			Description: "내 행동을 3인칭으로 알림",
+			Run:         runMe,
+		},
+		{
+			Name:        "shrug",
+			Usage:       "/shrug [메시지]",
+			Description: "메시지 끝에 " + shrug + " 추가",
+			Run:         runShrug,
+		},
+		{
+			Name:        "topic",
+			Usage:       "/topic <주제>",
+			Description: "채팅방 주제(설명) 변경",
+			Role:        roomActionRoles[roomActionUpdateSettings],
+			Run:         runTopic,
+		},
+		{
+			Name:        "invite",
+			Usage:       "/invite @사용자",
+			Description: "사용자에게 채팅방 초대 보내기",
+			Role:        roomActionRoles[roomActionManageInvites],
+			Run:         runInvite,
+		},
+		{
+			Name:        "mute",
+			Usage:       "/mute @사용자 <기간, 예: 10m, 2h, 1d> [사유]",
+			Description: "멤버를 일정 시간 음소거",
+			Role:        roomActionRoles[roomActionSanctionMembers],
+			Run:         runMute,
+		},
+		{
+			Name:        "poll",
+			Usage:       "/poll [--multiple] [--anonymous] 질문 | 선택지1 | 선택지2 ...",
+			Description: "투표 만들기",
+			Posts:       true,
+			Run:         runPoll,
+		},
+	} {
+		if err := RegisterCommand(cmd); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// builtinCommand returns the registered built-in called name, for usage errors.
+func builtinCommand(name string) Command {
+	cmd, _ := commands.lookup(name)
+	return cmd
+}
+
+// parseCommandUser returns the username in a "@username" argument.
+func parseCommandUser(arg string) (string, bool) {
+	m := commandUserPattern.FindStringSubmatch(arg)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// parseCommandDuration parses a Go duration or a whole number of days ("1d").
+func parseCommandDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, errors.New("invalid days")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, errors.New("invalid duration")
+	}
+	return d, nil
+}
+
+func runHelp(ctx CommandContext) (CommandResponse, error) {
+	lines := []string{"사용할 수 있는 명령어:"}
+	for _, cmd := range commands.available(ctx.Room, ctx.Username) {
+		line := cmd.Usage
+		if cmd.Description != "" {
+			line += " - " + cmd.Description
+		}
+		lines = append(lines, line)
+	}
+	return ephemeral(strings.Join(lines, "\n"))
+}
+
+func runMe(ctx CommandContext) (CommandResponse, error) {
+	if ctx.Args == "" {
+		return CommandResponse{}, usageError(builtinCommand("me"))
+	}
+	return public("* " + ctx.Username + " " + ctx.Args)
+}
+
+func runShrug(ctx CommandContext) (CommandResponse, error) {
+	return public(strings.TrimSpace(ctx.Args + " " + shrug))
+}
+
+func runTopic(ctx CommandContext) (CommandResponse, error) {
+	if ctx.Args == "" {
+		return CommandResponse{}, usageError(builtinCommand("topic"))
+	}
+	if ctx.Room.IsDM() {
+		return CommandResponse{}, CommandError("DM 설정은 변경할 수 없습니다")
+	}
+	if msg := validateRoomUpdate(UpdateRoomRequest{Description: &ctx.Args}); msg != "" {
+		return CommandResponse{}, CommandError(msg)
+	}
+
+	roomID := ctx.Room.ID.Hex()
+	description := html.EscapeString(ctx.Args)
+	updated, err := mongodb.UpdateRoomSettings(roomID, mongodb.RoomSettingsUpdate{Description: &description})
+	if err != nil {
+		return CommandResponse{}, err
+	}
+	logger.AuditLog("room_updated", ctx.Username,
+		zap.String("room_id", roomID),
+		zap.Strings("fields", []string{"description"}),
+		zap.String("via", "command"),
+	)
+	broadcastRoomUpdate(*updated, ctx.Username)
+	return ephemeral("채팅방 주제를 변경했습니다.")
+}
+
+func runInvite(ctx CommandContext) (CommandResponse, error) {
+	target, ok := parseCommandUser(ctx.Args)
+	if !ok {
+		return CommandResponse{}, usageError(builtinCommand("invite"))
+	}
+	if ctx.Room.IsDefault || ctx.Room.IsDM() {
+		return CommandResponse{}, CommandError("이 채팅방에는 초대할 수 없습니다")
+	}
+	if isRoomMember(ctx.Room, target) {
+		return ephemeral(fmt.Sprintf("@%s 님은 이미 채팅방 멤버입니다.", target))
+	}
+	if _, err := mongodb.FindUserByUsername(target); err != nil {
+		return CommandResponse{}, CommandError("사용자를 찾을 수 없습니다")
+	}
+
+	// The target joins only by accepting the invite, through the same
+	// checks as any invite link.
+	roomID := ctx.Room.ID.Hex()
+	invite, err := mongodb.CreateUserInvite(roomID, ctx.Username, target, time.Now().Add(userInviteLifetime))
+	if err != nil {
+		return CommandResponse{}, err
+	}
+	logger.AuditLog("room_invite_created", ctx.Username,
+		zap.String("room_id", roomID),
+		zap.String("invite_id", invite.ID.Hex()),
+		zap.String("invitee", target),
+		zap.String("via", "command"),
+	)
+	// Message carries the token to accept with POST /invites/:token/accept.
+	RoomMgr.NotifyUsers([]string{target}, mongodb.ChatMessage{
+		Event:     "ROOM_INVITE",
+		User:      ctx.Username,
+		Message:   invite.Token,
+		RoomID:    roomID,
+		MessageID: invite.ID.Hex(),
+		CreatedAt: invite.CreatedAt.Format(time.RFC3339),
+	})
+	return ephemeral(fmt.Sprintf("@%s 님에게 초대를 보냈습니다.", target))
+}
+
+func runMute(ctx CommandContext) (CommandResponse, error) {
+	fields := strings.Fields(ctx.Args)
+	if len(fields) < 2 {
+		return CommandResponse{}, usageError(builtinCommand("mute"))
+	}
+	target, ok := parseCommandUser(fields[0])
+	if !ok {
+		return CommandResponse{}, usageError(builtinCommand("mute"))
+	}
+	duration, err := parseCommandDuration(fields[1])
+	if err != nil {
+		return CommandResponse{}, usageError(builtinCommand("mute"))
+	}
+	if ctx.Room.IsDM() {
+		return CommandResponse{}, CommandError("DM에서는 사용할 수 없습니다")
+	}
+	if !roomCanActOn(ctx.Room, ctx.Username, target, roomActionSanctionMembers) {
+		return CommandResponse{}, CommandError("이 사용자를 제재할 권한이 없습니다")
+	}
+
+	req := SanctionRequest{
+		Duration: int64(duration / time.Second),
+		Reason:   strings.Join(fields[2:], " "),
+	}
+	expiresAt, err := validateSanction(mongodb.SanctionMute, &req, time.Now())
+	if err != nil {
+		return CommandResponse{}, CommandError(err.Error())
+	}
+
+	roomID := ctx.Room.ID.Hex()
+	if _, err := mongodb.SetRoomSanction(mongodb.RoomSanction{
+		RoomID:    roomID,
+		Username:  target,
+		Kind:      mongodb.SanctionMute,
+		Reason:    req.Reason,
+		CreatedBy: ctx.Username,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return CommandResponse{}, err
+	}
+	logger.AuditLog(sanctionAuditEvents[mongodb.SanctionMute][0], ctx.Username,
+		zap.String("room_id", roomID),
+		zap.String("target", target),
+		zap.String("reason", req.Reason),
+		zap.Time("expires_at", *expiresAt),
+		zap.String("via", "command"),
+	)
+	return ephemeral(fmt.Sprintf("@%s 님을 %s 동안 음소거했습니다.", target, formatRemaining(duration)))
+}
+
+func runPoll(ctx CommandContext) (CommandResponse, error) {
+	var req CreatePollRequest
+	args := ctx.Args
+	for {
+		if rest, ok := strings.CutPrefix(args, "--multiple"); ok {
+			req.Multiple, args = true, strings.TrimSpace(rest)
+		} else if rest, ok := strings.CutPrefix(args, "--anonymous"); ok {
+			req.Anonymous, args = true, strings.TrimSpace(rest)
+		} else {
+			break
+		}
+	}
+	parts := strings.Split(args, "|")
+	if len(parts) < 1+minPollOptions {
+		return CommandResponse{}, usageError(builtinCommand("poll"))
+	}
+	req.Question, req.Options = parts[0], parts[1:]
+
+	now := time.Now()
+	if msg := validatePoll(&req, now); msg != "" {
+		return CommandResponse{}, CommandError(msg)
+	}
+	if _, err := createPoll(&ctx.Room, ctx.Username, req, now); err != nil {
+		return CommandResponse{}, err
+	}
+	return CommandResponse{}, nil
+}
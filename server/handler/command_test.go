@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"github.com/woonglife62/woongkie-talkie/server/middleware"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseCommand(t *testing.T) {
+	name, args, ok := parseCommand("/Topic  Weekly sync ")
+	assert.True(t, ok)
+	assert.Equal(t, "topic", name)
+	assert.Equal(t, "Weekly sync", args)
+
+	name, args, ok = parseCommand("/shrug")
+	assert.True(t, ok)
+	assert.Equal(t, "shrug", name)
+	assert.Empty(t, args)
+
+	for _, text := range []string{"hello", "//not a command", "/", "/ topic", " /me"} {
+		_, _, ok := parseCommand(text)
+		assert.False(t, ok, text)
+	}
+}
+
+// TestRegisterCommand verifies bad and duplicate registrations are refused.
+func TestRegisterCommand(t *testing.T) {
+	run := func(CommandContext) (CommandResponse, error) { return ephemeral("pong") }
+
+	assert.Error(t, RegisterCommand(Command{Name: "Bad Name", Run: run}))
+	assert.Error(t, RegisterCommand(Command{Name: "norun"}))
+	assert.Error(t, RegisterCommand(Command{Name: "badrole", Role: "janitor", Run: run}))
+	assert.Error(t, RegisterCommand(Command{Name: "shrug", Run: run}))
+
+	assert.NoError(t, RegisterCommand(Command{Name: "test-ping", Role: mongodb.RoomRoleModerator, Run: run}))
+	defer func() {
+		commands.mu.Lock()
+		delete(commands.commands, "test-ping")
+		commands.mu.Unlock()
+	}()
+	cmd, ok := commands.lookup("test-ping")
+	assert.True(t, ok)
+	assert.Equal(t, "/test-ping", cmd.Usage)
+
+	// Only moderators and up see the moderator command.
+	room := roleTestRoom()
+	names := func(username string) []string {
+		var list []string
+		for _, cmd := range commands.available(room, username) {
+			list = append(list, cmd.Name)
+		}
+		return list
+	}
+	assert.Contains(t, names("mike"), "test-ping")
+	assert.NotContains(t, names("mary"), "test-ping")
+	assert.Contains(t, names("mary"), "shrug")
+	assert.NotContains(t, names("mary"), "mute")
+}
+
+func TestBuiltinCommands_PublicText(t *testing.T) {
+	ctx := CommandContext{Room: roleTestRoom(), Username: "mary", Args: "waves"}
+
+	resp, err := runMe(ctx)
+	assert.NoError(t, err)
+	assert.False(t, resp.Ephemeral)
+	assert.Equal(t, "* mary waves", resp.Text)
+
+	resp, err = runShrug(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "waves "+shrug, resp.Text)
+
+	ctx.Args = ""
+	resp, _ = runShrug(ctx)
+	assert.Equal(t, shrug, resp.Text)
+	_, err = runMe(ctx)
+	assert.IsType(t, CommandError(""), err)
+}
+
+func TestParseCommandArgs(t *testing.T) {
+	user, ok := parseCommandUser("@mike")
+	assert.True(t, ok)
+	assert.Equal(t, "mike", user)
+	_, ok = parseCommandUser("@m")
+	assert.False(t, ok)
+
+	d, err := parseCommandDuration("10m")
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, d)
+	d, err = parseCommandDuration("2d")
+	assert.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, d)
+	for _, s := range []string{"", "0d", "-5m", "soon"} {
+		_, err := parseCommandDuration(s)
+		assert.Error(t, err, s)
+	}
+}
+
+// TestRunMute_RequiresTarget verifies malformed /mute arguments get the usage.
+func TestRunMute_RequiresTarget(t *testing.T) {
+	_, err := runMute(CommandContext{Room: roleTestRoom(), Username: "mike", Args: "@mary"})
+	assert.EqualError(t, err, "사용법: "+builtinCommand("mute").Usage)
+}
+
+// TestRunInvite_NoDirectJoin verifies /invite refuses rooms without invites
+// and answers for members without inviting them again.
+func TestRunInvite_NoDirectJoin(t *testing.T) {
+	_, err := runInvite(CommandContext{Room: mongodb.Room{IsDefault: true}, Username: "mike", Args: "@mary"})
+	assert.Error(t, err)
+
+	resp, err := runInvite(CommandContext{Room: roleTestRoom(), Username: "mike", Args: "@mary"})
+	if assert.NoError(t, err) {
+		assert.True(t, resp.Ephemeral)
+		assert.Contains(t, resp.Text, "이미 채팅방 멤버")
+	}
+
+	assert.True(t, isAllowedEvent("ROOM_INVITE"))
+	assert.False(t, clientEvents["ROOM_INVITE"])
+}
+
+// Test commands run through readPump, counting how often they run.
+var (
+	registerTestCommands sync.Once
+	testCommandRuns      atomic.Int32
+	testModCommandRuns   atomic.Int32
+)
+
+// useTestCommands registers the counting commands and resets their counts.
+func useTestCommands(t *testing.T) {
+	registerTestCommands.Do(func() {
+		assert.NoError(t, RegisterCommand(Command{
+			Name: "test-count",
+			Run: func(CommandContext) (CommandResponse, error) {
+				testCommandRuns.Add(1)
+				return ephemeral("counted")
+			},
+		}))
+		assert.NoError(t, RegisterCommand(Command{
+			Name: "test-mod",
+			Role: mongodb.RoomRoleModerator,
+			Run: func(CommandContext) (CommandResponse, error) {
+				testModCommandRuns.Add(1)
+				return ephemeral("moderated")
+			},
+		}))
+	})
+	testCommandRuns.Store(0)
+	testModCommandRuns.Store(0)
+}
+
+// startReadPump serves c over a test WebSocket with its readPump and
+// writePump running, loading its room from room, and returns the client end.
+func startReadPump(t *testing.T, c *Client, room *mongodb.Room) *websocket.Conn {
+	t.Helper()
+	findRoom := findClientRoom
+	findClientRoom = func(string) (*mongodb.Room, error) { return room, nil }
+	t.Cleanup(func() { findClientRoom = findRoom })
+
+	c.Hub = &Hub{
+		Broadcast:  make(chan mongodb.ChatMessage, 16),
+		Unregister: make(chan *Client, 1),
+		stop:       make(chan struct{}),
+	}
+	c.Send = make(chan mongodb.ChatMessage, 16)
+	c.msgLimit = middleware.NewWSMessageLimiter()
+	srv := newTestServer(t, func(conn *websocket.Conn) {
+		c.Conn = conn
+		go c.writePump()
+		c.readPump()
+	})
+	t.Cleanup(srv.Close)
+	conn := dialWS(t, srv, "/")
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readEvent returns the next event the server sent on conn.
+func readEvent(t *testing.T, conn *websocket.Conn) mongodb.ChatMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg mongodb.ChatMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("no event received: %v", err)
+	}
+	return msg
+}
+
+// TestReadPump_CommandDedup verifies a command resent with the same
+// client_msg_id runs once.
+func TestReadPump_CommandDedup(t *testing.T) {
+	useTestCommands(t)
+	room := &mongodb.Room{ID: primitive.NewObjectID(), Members: []string{"mary"}}
+	c := &Client{Username: "mary", RoomID: room.ID.Hex(), muteCheckedAt: time.Now()}
+	conn := startReadPump(t, c, room)
+
+	for _, id := range []string{"cmd-1", "cmd-1", "cmd-2"} {
+		assert.NoError(t, conn.WriteJSON(mongodb.ChatMessage{Event: "MSG", Message: "/test-count", ClientMsgID: id}))
+	}
+	assert.Equal(t, "COMMAND", readEvent(t, conn).Event)
+	assert.Equal(t, "COMMAND", readEvent(t, conn).Event)
+	// readPump handles messages in order, so the duplicate was dropped
+	// before cmd-2 answered.
+	assert.Equal(t, int32(2), testCommandRuns.Load())
+}
+
+// TestReadPump_CommandMuted verifies a muted member cannot run commands,
+// even ones that do not post.
+func TestReadPump_CommandMuted(t *testing.T) {
+	useTestCommands(t)
+	room := &mongodb.Room{ID: primitive.NewObjectID(), Members: []string{"mary"}}
+	c := &Client{
+		Username:      "mary",
+		RoomID:        room.ID.Hex(),
+		mute:          &mongodb.RoomSanction{Kind: mongodb.SanctionMute},
+		muteCheckedAt: time.Now(),
+	}
+	conn := startReadPump(t, c, room)
+
+	assert.NoError(t, conn.WriteJSON(mongodb.ChatMessage{Event: "MSG", Message: "/test-count"}))
+	warn := readEvent(t, conn)
+	assert.Equal(t, "WARN", warn.Event)
+	assert.Equal(t, muteWarning(c.mute, time.Now()), warn.Message)
+	assert.Zero(t, testCommandRuns.Load())
+}
+
+// TestReadPump_CommandArchived verifies commands do not run in an archived
+// room.
+func TestReadPump_CommandArchived(t *testing.T) {
+	useTestCommands(t)
+	room := &mongodb.Room{ID: primitive.NewObjectID(), Members: []string{"mary"}, Archived: true}
+	c := &Client{Username: "mary", RoomID: room.ID.Hex(), muteCheckedAt: time.Now()}
+	conn := startReadPump(t, c, room)
+
+	assert.NoError(t, conn.WriteJSON(mongodb.ChatMessage{Event: "MSG", Message: "/test-count"}))
+	warn := readEvent(t, conn)
+	assert.Equal(t, "WARN", warn.Event)
+	assert.Equal(t, archivedWarning, warn.Message)
+	assert.Zero(t, testCommandRuns.Load())
+}
+
+// TestReadPump_CommandRoleIsFresh verifies a command's role check uses the
+// room as stored, not the socket's cached copy.
+func TestReadPump_CommandRoleIsFresh(t *testing.T) {
+	useTestCommands(t)
+	demoted := roleTestRoom()
+	demoted.ID = primitive.NewObjectID()
+	delete(demoted.Roles, "mike")
+	cached := roleTestRoom()
+	c := &Client{
+		Username:      "mike",
+		RoomID:        demoted.ID.Hex(),
+		room:          &cached,
+		roomCheckedAt: time.Now(),
+		muteCheckedAt: time.Now(),
+	}
+	conn := startReadPump(t, c, &demoted)
+
+	assert.NoError(t, conn.WriteJSON(mongodb.ChatMessage{Event: "MSG", Message: "/test-mod"}))
+	warn := readEvent(t, conn)
+	assert.Equal(t, "WARN", warn.Event)
+	assert.Contains(t, warn.Message, "권한이 없습니다")
+	assert.Zero(t, testModCommandRuns.Load())
+}
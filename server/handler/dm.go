@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/config"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.uber.org/zap"
+)
+
+type CreateGroupDMRequest struct {
+	Usernames []string `json:"usernames"` // other participants; the caller is always included
+}
+
+// openDM finds or creates the DM between the caller and others and writes it
+// to the response: 201 when it was just created, 200 when it already existed.
+func openDM(c echo.Context, username string, participants []string, maxMembers int) error {
+	room, created, err := mongodb.FindOrCreateDM(username, participants, maxMembers)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DM 생성에 실패했습니다"})
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+		logger.AuditLog("dm_created", username,
+			zap.String("room_id", room.ID.Hex()),
+			zap.Strings("participants", room.Members),
+			zap.String("ip", c.RealIP()),
+		)
+	}
+	return c.JSON(status, roomToResponse(*room, RoomMgr.GetOnlineMembers(room.ID.Hex())))
+}
+
+// POST /dm/:username
+func OpenDMHandler(c echo.Context) error {
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+	target := c.Param("username")
+	if target == "" || target == username {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "DM 상대를 지정해주세요"})
+	}
+
+	user, err := mongodb.FindUserByUsername(target)
+	if err != nil || user.Role == "blocked" {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "사용자를 찾을 수 없습니다"})
+	}
+
+	return openDM(c, username, []string{username, target}, 2)
+}
+
+// POST /dm
+func CreateGroupDMHandler(c echo.Context) error {
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	var req CreateGroupDMRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+	participants := mongodb.DMParticipants(append(req.Usernames, username))
+	if len(participants) < 2 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "DM 상대를 지정해주세요"})
+	}
+	if len(participants) > config.DMMaxMembers {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("DM 참여자는 최대 %d명입니다", config.DMMaxMembers)})
+	}
+
+	existing, err := mongodb.FindExistingUsernames(participants)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DM 생성에 실패했습니다"})
+	}
+	if len(existing) != len(participants) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "사용자를 찾을 수 없습니다"})
+	}
+
+	maxMembers := config.DMMaxMembers
+	if len(participants) == 2 {
+		maxMembers = 2
+	}
+	return openDM(c, username, participants, maxMembers)
+}
+
+// GET /dm
+// Room prefs apply as on GET /rooms, including ?include_hidden=true.
+func ListDMsHandler(c echo.Context) error {
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	rooms, err := mongodb.FindDMs(username)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DM 목록 조회에 실패했습니다"})
+	}
+	return c.JSON(http.StatusOK, roomListResponse(c, rooms, username))
+}
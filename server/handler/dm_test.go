@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/woonglife62/woongkie-talkie/pkg/config"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+// TestDMKey_OrderIndependent verifies a DM is identified by its participant set.
+func TestDMKey_OrderIndependent(t *testing.T) {
+	assert.Equal(t, mongodb.DMKey([]string{"bob", "alice"}), mongodb.DMKey([]string{"alice", "bob", "alice"}))
+	assert.NotEqual(t, mongodb.DMKey([]string{"alice", "bob"}), mongodb.DMKey([]string{"alice", "bob", "carol"}))
+	assert.Equal(t, []string{"alice", "bob"}, mongodb.DMParticipants([]string{"bob", "", "alice", "bob"}))
+}
+
+// TestRoomToResponse_DMParticipants verifies only DMs expose their members.
+func TestRoomToResponse_DMParticipants(t *testing.T) {
+	dm := mongodb.Room{Kind: mongodb.RoomKindDM, Members: []string{"alice", "bob"}}
+	resp := roomToResponse(dm, nil)
+	assert.Equal(t, mongodb.RoomKindDM, resp.Kind)
+	assert.Equal(t, []string{"alice", "bob"}, resp.Participants)
+
+	room := mongodb.Room{Name: "general", Members: []string{"alice", "bob"}}
+	assert.Nil(t, roomToResponse(room, nil).Participants)
+}
+
+// TestOpenDMHandler_Self verifies a DM with yourself is rejected before any database access.
+func TestOpenDMHandler_Self(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/dm/alice", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("username")
+	c.SetParamValues("alice")
+	c.Set("username", "alice")
+
+	err := OpenDMHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// TestCreateGroupDMHandler_TooManyParticipants verifies the group DM size limit.
+func TestCreateGroupDMHandler_TooManyParticipants(t *testing.T) {
+	names := make([]string, config.DMMaxMembers)
+	for i := range names {
+		names[i] = `"user` + strings.Repeat("x", i+1) + `"`
+	}
+	body := `{"usernames":[` + strings.Join(names, ",") + `]}`
+
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/dm", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("username", "alice")
+
+	err := CreateGroupDMHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// TestCreateGroupDMHandler_NoOthers verifies a group DM needs another participant.
+func TestCreateGroupDMHandler_NoOthers(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/dm", strings.NewReader(`{"usernames":["alice"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("username", "alice")
+
+	err := CreateGroupDMHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+const (
+	// maxMessageTTLSeconds caps the self-destruct timer of a message or room.
+	maxMessageTTLSeconds = 7 * 24 * 60 * 60
+	// expireSweepInterval is how often expired messages are deleted.
+	expireSweepInterval = 2 * time.Second
+	// expireSweepBatch bounds the messages deleted per sweep.
+	expireSweepBatch = 500
+	// expireSweepKey is claimed by the instance running a sweep.
+	expireSweepKey = "expire:sweep"
+)
+
+var (
+	expireStop chan struct{}
+	expireWg   sync.WaitGroup
+)
+
+// messageTTL returns how long a message sent to room with the requested
+// expires_in (seconds) lives, or 0 for a permanent message. The room's timer
+// applies unless the sender asks for a shorter one.
+func messageTTL(room *mongodb.Room, requested int64) time.Duration {
+	ttl := time.Duration(requested) * time.Second
+	if room != nil && room.MessageTTLSeconds > 0 {
+		if roomTTL := time.Duration(room.MessageTTLSeconds) * time.Second; ttl <= 0 || ttl > roomTTL {
+			ttl = roomTTL
+		}
+	}
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// validExpiresIn reports whether a requested expires_in is acceptable.
+func validExpiresIn(seconds int64) bool {
+	return seconds >= 0 && seconds <= maxMessageTTLSeconds
+}
+
+// expiresAt formats the expiry of a message created at t with ttl, or ""
+// for a permanent message.
+func expiresAt(t time.Time, ttl time.Duration) string {
+	if ttl <= 0 {
+		return ""
+	}
+	return t.Add(ttl).Format("2006-01-02T15:04:05Z07:00")
+}
+
+// StartMessageExpirer deletes expired ephemeral messages every
+// expireSweepInterval and tells their rooms with MSG_EXPIRE, until
+// StopMessageExpirer is called. Expiry lives in MongoDB, so messages that
+// expired while no server ran are removed on the first sweep after a restart.
+func StartMessageExpirer() {
+	expireStop = make(chan struct{})
+	expireWg.Add(1)
+	go func() {
+		defer expireWg.Done()
+		ticker := time.NewTicker(expireSweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if claimOnce(expireSweepKey, expireSweepInterval*9/10) {
+					expireMessages(time.Now())
+				}
+			case <-expireStop:
+				return
+			}
+		}
+	}()
+}
+
+// StopMessageExpirer stops the expirer and waits for a running sweep to finish.
+func StopMessageExpirer() {
+	if expireStop == nil {
+		return
+	}
+	close(expireStop)
+	expireWg.Wait()
+}
+
+// expireMessages deletes the messages expired at now and broadcasts an
+// MSG_EXPIRE for each to every instance.
+func expireMessages(now time.Time) {
+	expired, err := mongodb.DeleteExpiredChats(now, expireSweepBatch)
+	if err != nil {
+		logger.Logger.Errorw("DeleteExpiredChats failed", "error", err)
+		return
+	}
+	for _, chat := range expired {
+		RoomMgr.Broadcast(chat.RoomID, mongodb.ChatMessage{
+			Event:     "MSG_EXPIRE",
+			User:      "system",
+			RoomID:    chat.RoomID,
+			MessageID: chat.ID.Hex(),
+			Seq:       chat.Seq,
+		})
+		// An expired reply no longer counts towards its thread.
+		if chat.ReplyTo != "" {
+			if parent, err := mongodb.UpdateThreadSummary(chat.ReplyTo, -1, time.Time{}); err == nil {
+				broadcastThreadUpdate(chat.RoomID, parent)
+			}
+		}
+	}
+}
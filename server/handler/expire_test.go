@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+// TestMessageTTL verifies the room timer applies unless the sender asks for a
+// shorter one.
+func TestMessageTTL(t *testing.T) {
+	assert.Zero(t, messageTTL(nil, 0))
+	assert.Equal(t, 30*time.Second, messageTTL(nil, 30))
+
+	room := &mongodb.Room{MessageTTLSeconds: 60}
+	assert.Equal(t, time.Minute, messageTTL(room, 0))
+	assert.Equal(t, 10*time.Second, messageTTL(room, 10))
+	assert.Equal(t, time.Minute, messageTTL(room, 3600))
+
+	assert.Equal(t, 30*time.Second, messageTTL(&mongodb.Room{}, 30))
+}
+
+func TestValidExpiresIn(t *testing.T) {
+	assert.True(t, validExpiresIn(0))
+	assert.True(t, validExpiresIn(maxMessageTTLSeconds))
+	assert.False(t, validExpiresIn(-1))
+	assert.False(t, validExpiresIn(maxMessageTTLSeconds+1))
+}
+
+func TestExpiresAt(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "", expiresAt(now, 0))
+	assert.Equal(t, "2026-01-01T00:01:00Z", expiresAt(now, time.Minute))
+}
+
+// TestChatToMessage_ExpiresAt verifies replayed ephemeral messages keep their expiry.
+func TestChatToMessage_ExpiresAt(t *testing.T) {
+	exp := time.Date(2026, 1, 1, 0, 1, 0, 0, time.UTC)
+	msg := chatToMessage(mongodb.Chat{Message: "secret", ExpiresAt: &exp}, "CHATLOG", "alice")
+	assert.Equal(t, "2026-01-01T00:01:00Z", msg.ExpiresAt)
+
+	msg = chatToMessage(mongodb.Chat{Message: "hello"}, "CHATLOG", "alice")
+	assert.Empty(t, msg.ExpiresAt)
+}
+
+func TestAllowedEvents_MsgExpire(t *testing.T) {
+	assert.True(t, isAllowedEvent("MSG_EXPIRE"))
+	assert.False(t, clientEvents["MSG_EXPIRE"])
+}
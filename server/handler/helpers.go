@@ -17,7 +17,8 @@ func GetUsername(c echo.Context) string {
 
 // requireRoomMember verifies the authenticated user is a member of the room
 // identified by the ":id" path parameter.
-// Public rooms (IsPublic=true) and the default room are always accessible.
+// Public rooms anyone may join (join_policy open) and the default room are
+// always accessible; a public room with another policy is only listed.
 // Returns a non-nil echo.HTTPError when access should be denied, nil otherwise.
 func requireRoomMember(c echo.Context) error {
 	roomID := c.Param("id")
@@ -30,21 +31,171 @@ func requireRoomMember(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusNotFound, "채팅방을 찾을 수 없습니다")
 	}
 
-	// Public rooms and the default room are open to everyone.
-	if room.IsPublic || room.IsDefault {
+	username := GetUsername(c)
+
+	// A room ban applies even to public rooms and the default room.
+	if username != "" {
+		ban, err := mongodb.FindActiveSanction(roomID, username, mongodb.SanctionBan)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "차단 여부를 확인하지 못했습니다")
+		}
+		if ban != nil {
+			return echo.NewHTTPError(http.StatusForbidden, "이 채팅방에서 차단되었습니다")
+		}
+	}
+
+	return roomAccessError(*room, username)
+}
+
+// roomAccessError decides whether username may read room once bans have been
+// checked. Returns nil when access is allowed.
+func roomAccessError(room mongodb.Room, username string) error {
+	// Open public rooms and the default room are readable by everyone. Other
+	// join policies must not be bypassed by reading without joining.
+	if room.IsDefault || (room.IsPublic && room.EffectiveJoinPolicy() == mongodb.JoinPolicyOpen) {
 		return nil
 	}
 
-	username := GetUsername(c)
 	if username == "" {
 		return echo.NewHTTPError(http.StatusUnauthorized, "인증이 필요합니다")
 	}
 
+	if isRoomMember(room, username) {
+		return nil
+	}
+
+	return echo.NewHTTPError(http.StatusForbidden, "채팅방 멤버만 접근할 수 있습니다")
+}
+
+// isRoomMember reports whether username is listed in room.Members.
+func isRoomMember(room mongodb.Room, username string) bool {
 	for _, member := range room.Members {
 		if member == username {
-			return nil
+			return true
 		}
 	}
+	return false
+}
 
-	return echo.NewHTTPError(http.StatusForbidden, "채팅방 멤버만 접근할 수 있습니다")
+// roomAction is something a member may or may not do in a room, depending on
+// their room role.
+type roomAction int
+
+const (
+	roomActionDeleteMessages roomAction = iota // delete other members' messages
+	roomActionViewRevisions                    // read other members' edit history
+	roomActionManagePins
+	roomActionKickMembers
+	roomActionSanctionMembers // ban and mute
+	roomActionManageInvites
+	roomActionDecideJoinRequests
+	roomActionUpdateSettings // name, description, password, limits, join policy
+	roomActionBypassSlowMode
+	roomActionPostReadOnly // send in a read-only room without being on its allow-list
+	roomActionManageSlowMode
+	roomActionManageRetention // message retention policy
+	roomActionManageRoles     // promote and demote moderators
+	roomActionTransferOwnership
+	roomActionArchiveRoom // archive and unarchive
+	roomActionDeleteRoom
+)
+
+// roomActionRoles maps each action to the least privileged role allowed to do it.
+var roomActionRoles = map[roomAction]string{
+	roomActionDeleteMessages:     mongodb.RoomRoleModerator,
+	roomActionViewRevisions:      mongodb.RoomRoleModerator,
+	roomActionManagePins:         mongodb.RoomRoleModerator,
+	roomActionKickMembers:        mongodb.RoomRoleModerator,
+	roomActionSanctionMembers:    mongodb.RoomRoleModerator,
+	roomActionManageInvites:      mongodb.RoomRoleModerator,
+	roomActionDecideJoinRequests: mongodb.RoomRoleModerator,
+	roomActionUpdateSettings:     mongodb.RoomRoleModerator,
+	roomActionBypassSlowMode:     mongodb.RoomRoleModerator,
+	roomActionPostReadOnly:       mongodb.RoomRoleModerator,
+	roomActionManageSlowMode:     mongodb.RoomRoleOwner,
+	roomActionManageRetention:    mongodb.RoomRoleOwner,
+	roomActionManageRoles:        mongodb.RoomRoleOwner,
+	roomActionTransferOwnership:  mongodb.RoomRoleOwner,
+	roomActionArchiveRoom:        mongodb.RoomRoleOwner,
+	roomActionDeleteRoom:         mongodb.RoomRoleOwner,
+}
+
+// roomRoleRank orders room roles; a higher rank includes every lower one.
+var roomRoleRank = map[string]int{
+	mongodb.RoomRoleMember:    1,
+	mongodb.RoomRoleModerator: 2,
+	mongodb.RoomRoleOwner:     3,
+}
+
+// roomCan reports whether username may perform action in room. It is the one
+// place room permissions are decided: the user's room role must rank at least
+// the action's role, and global admins may do everything.
+func roomCan(room mongodb.Room, username string, action roomAction) bool {
+	required, ok := roomActionRoles[action]
+	if !ok {
+		return false
+	}
+	return roomHasRole(room, username, required)
+}
+
+// roomHasRole reports whether username's room role ranks at least required,
+// or they are a global admin.
+func roomHasRole(room mongodb.Room, username, required string) bool {
+	if username == "" {
+		return false
+	}
+	if roomRoleRank[room.RoleOf(username)] >= roomRoleRank[required] {
+		return true
+	}
+	user, err := mongodb.FindUserByUsername(username)
+	return err == nil && user.Role == "admin"
+}
+
+// roomCanActOn reports whether actor may perform action against target in
+// room: actor needs the action's role and must outrank target. Nobody can act
+// on themselves or on the owner; global admins outrank everyone else.
+func roomCanActOn(room mongodb.Room, actor, target string, action roomAction) bool {
+	if actor == target || room.RoleOf(target) == mongodb.RoomRoleOwner {
+		return false
+	}
+	if !roomCan(room, actor, action) {
+		return false
+	}
+	if roomRoleRank[room.RoleOf(actor)] > roomRoleRank[room.RoleOf(target)] {
+		return true
+	}
+	user, err := mongodb.FindUserByUsername(actor)
+	return err == nil && user.Role == "admin"
+}
+
+// roomCanPost reports whether username may send messages in room: anyone in
+// a normal room, but only moderators and the allow-list in a read-only one.
+func roomCanPost(room mongodb.Room, username string) bool {
+	if !room.ReadOnly {
+		return true
+	}
+	for _, poster := range room.Posters {
+		if poster == username {
+			return true
+		}
+	}
+	return roomCan(room, username, roomActionPostReadOnly)
+}
+
+// requireRoomPermission loads the room identified by the ":id" path parameter
+// and verifies the authenticated user may perform action in it.
+// Returns a non-nil echo.HTTPError when access should be denied.
+func requireRoomPermission(c echo.Context, action roomAction) (*mongodb.Room, error) {
+	username := GetUsername(c)
+	if username == "" {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "인증이 필요합니다")
+	}
+	room, err := mongodb.FindRoomByID(c.Param("id"))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "채팅방을 찾을 수 없습니다")
+	}
+	if !roomCan(*room, username, action) {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "권한이 없습니다")
+	}
+	return room, nil
 }
@@ -220,17 +220,31 @@ func (h *Hub) Run() {
 
 // allowedEvents is the whitelist of event types accepted from Redis Pub/Sub.
 var allowedEvents = map[string]bool{
-	"MSG":          true,
-	"MSG_FILE":     true,
-	"MSG_EDIT":     true,
-	"MSG_DELETE":   true,
-	"OPEN":         true,
-	"CLOSE":        true,
-	"CHATLOG":      true,
-	"PRESENCE":     true,
-	"TYPING_START": true,
-	"TYPING_STOP":  true,
-	"WARN":         true,
+	"MSG":             true,
+	"MSG_FILE":        true,
+	"MSG_EDIT":        true,
+	"MSG_DELETE":      true,
+	"MSG_EXPIRE":      true,
+	"OPEN":            true,
+	"CLOSE":           true,
+	"CHATLOG":         true,
+	"PRESENCE":        true,
+	"TYPING_START":    true,
+	"TYPING_STOP":     true,
+	"WARN":            true,
+	"REACTION_ADD":    true,
+	"REACTION_REMOVE": true,
+	"THREAD_UPDATE":   true,
+	"READ":            true,
+	"PIN_ADD":         true,
+	"PIN_REMOVE":      true,
+	"MENTION":         true,
+	"ROLE_UPDATE":     true,
+	"JOIN_REQUEST":    true,
+	"JOIN_DECISION":   true,
+	"ROOM_INVITE":     true,
+	"ROOM_UPDATE":     true,
+	"POLL_UPDATE":     true,
 }
 
 // isAllowedEvent returns true if the event type is in the whitelist.
@@ -287,9 +301,8 @@ func (h *Hub) broadcastLocal(msg mongodb.ChatMessage) {
 		} else if clientMsg.Event == "CLOSE" {
 			clientMsg.Message = fmt.Sprintf("---- %s님이 퇴장하셨습니다. ----", clientMsg.User)
 		}
-		select {
-		case client.Send <- clientMsg:
-		default:
+		// trySend guards against a concurrent KickUser closing Send.
+		if !client.trySend(clientMsg) {
 			// Slow client: mark for eviction.
 			evict = append(evict, client)
 		}
@@ -314,8 +327,10 @@ func (h *Hub) broadcastLocal(msg mongodb.ChatMessage) {
 	}
 }
 
-// KickUser forcibly disconnects all connections for the given username (#242).
-func (h *Hub) KickUser(username string) {
+// KickUser forcibly disconnects all connections for the given username on this
+// instance, sending reason in the close frame (#242). Use RoomMgr.KickUser to
+// reach every instance.
+func (h *Hub) KickUser(username, reason string) {
 	h.mu.Lock()
 	var toEvict []*Client
 	for client := range h.Clients {
@@ -324,7 +339,7 @@ func (h *Hub) KickUser(username string) {
 		}
 	}
 	for _, client := range toEvict {
-		client.closeSend()
+		client.closeWithReason(reason)
 		delete(h.Clients, client)
 	}
 	h.mu.Unlock()
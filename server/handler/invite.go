@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxInviteLifetime bounds expires_in on new invites.
+	maxInviteLifetime = 30 * 24 * time.Hour
+	// maxInviteUses bounds max_uses on new invites.
+	maxInviteUses = 1000
+	// userInviteLifetime is how long a personal invite sent with /invite lasts.
+	userInviteLifetime = 7 * 24 * time.Hour
+)
+
+// inviteTokenPattern matches tokens issued by mongodb.CreateRoomInvite.
+var inviteTokenPattern = regexp.MustCompile(`^[a-f0-9]{32}$`)
+
+type CreateInviteRequest struct {
+	ExpiresIn int `json:"expires_in"` // seconds; 0 = never expires
+	MaxUses   int `json:"max_uses"`   // 0 = unlimited
+}
+
+// requireInviteManager loads the room for invite management. The default
+// room and DMs have no invites.
+func requireInviteManager(c echo.Context) (*mongodb.Room, error) {
+	room, err := requireRoomPermission(c, roomActionManageInvites)
+	if err != nil {
+		return nil, err
+	}
+	if room.IsDefault || room.IsDM() {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "이 채팅방에는 초대 링크를 만들 수 없습니다")
+	}
+	return room, nil
+}
+
+// POST /rooms/:id/invites
+func CreateInviteHandler(c echo.Context) error {
+	var req CreateInviteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+	if req.ExpiresIn < 0 || time.Duration(req.ExpiresIn)*time.Second > maxInviteLifetime {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "만료 시간은 0초 이상 30일 이하이어야 합니다"})
+	}
+	if req.MaxUses < 0 || req.MaxUses > maxInviteUses {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "최대 사용 횟수는 0 이상 1000 이하이어야 합니다"})
+	}
+
+	room, err := requireInviteManager(c)
+	if err != nil {
+		return err
+	}
+	roomID := room.ID.Hex()
+	username := GetUsername(c)
+
+	var expiresAt *time.Time
+	if req.ExpiresIn > 0 {
+		t := time.Now().Add(time.Duration(req.ExpiresIn) * time.Second)
+		expiresAt = &t
+	}
+
+	invite, err := mongodb.CreateRoomInvite(roomID, username, expiresAt, req.MaxUses)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "초대 링크 생성에 실패했습니다"})
+	}
+
+	logger.AuditLog("room_invite_created", username,
+		zap.String("room_id", roomID),
+		zap.String("invite_id", invite.ID.Hex()),
+		zap.Int("max_uses", req.MaxUses),
+		zap.Int("expires_in", req.ExpiresIn),
+		zap.String("ip", c.RealIP()),
+	)
+	return c.JSON(http.StatusCreated, invite)
+}
+
+// GET /rooms/:id/invites
+func ListInvitesHandler(c echo.Context) error {
+	room, err := requireInviteManager(c)
+	if err != nil {
+		return err
+	}
+
+	invites, err := mongodb.FindRoomInvites(room.ID.Hex())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "초대 링크 조회에 실패했습니다"})
+	}
+	return c.JSON(http.StatusOK, invites)
+}
+
+// DELETE /rooms/:id/invites/:inviteId
+func DeleteInviteHandler(c echo.Context) error {
+	room, err := requireInviteManager(c)
+	if err != nil {
+		return err
+	}
+	roomID := room.ID.Hex()
+	inviteID := c.Param("inviteId")
+	username := GetUsername(c)
+
+	if err := mongodb.DeleteRoomInvite(roomID, inviteID); err != nil {
+		if errors.Is(err, mongodb.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "초대 링크를 찾을 수 없습니다"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "초대 링크 삭제에 실패했습니다"})
+	}
+
+	logger.AuditLog("room_invite_revoked", username,
+		zap.String("room_id", roomID),
+		zap.String("invite_id", inviteID),
+		zap.String("ip", c.RealIP()),
+	)
+	return c.JSON(http.StatusOK, map[string]string{"message": "초대 링크가 삭제되었습니다"})
+}
+
+// GET /users/me/invites
+// Lists the personal invites sent to the caller that can still be accepted.
+func ListMyInvitesHandler(c echo.Context) error {
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+	invites, err := mongodb.FindUserInvites(username)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "초대 조회에 실패했습니다"})
+	}
+	return c.JSON(http.StatusOK, invites)
+}
+
+// POST /invites/:token/accept
+// A valid invite joins the room without its password, still bounded by MaxMembers.
+func AcceptInviteHandler(c echo.Context) error {
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+	token := c.Param("token")
+	if !inviteTokenPattern.MatchString(token) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "유효하지 않거나 만료된 초대 링크입니다"})
+	}
+
+	invite, err := mongodb.ClaimRoomInvite(token, username)
+	if err != nil {
+		if errors.Is(err, mongodb.ErrNotFound) {
+			logger.AuditLog("room_invite_rejected", username, zap.String("reason", "invalid_or_expired"), zap.String("ip", c.RealIP()))
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "유효하지 않거나 만료된 초대 링크입니다"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "초대 수락에 실패했습니다"})
+	}
+	// release gives the claimed use back when no join happens.
+	release := func() {
+		if err := mongodb.ReleaseRoomInvite(invite.ID); err != nil {
+			logger.Logger.Warnw("ReleaseRoomInvite failed", "invite_id", invite.ID.Hex(), "error", err)
+		}
+	}
+
+	room, err := mongodb.FindRoomByID(invite.RoomID)
+	if err != nil {
+		release()
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방을 찾을 수 없습니다"})
+	}
+	if isRoomMember(*room, username) {
+		release()
+		return c.JSON(http.StatusOK, roomToResponse(*room, RoomMgr.GetOnlineMembers(invite.RoomID)))
+	}
+
+	if err := mongodb.JoinRoom(invite.RoomID, username); err != nil {
+		release()
+		reason := "join_failed"
+		if errors.Is(err, mongodb.ErrBanned) {
+			reason = "banned"
+		} else if errors.Is(err, mongodb.ErrRoomArchived) {
+			reason = "archived"
+		}
+		logger.AuditLog("room_join_failed", username,
+			zap.String("room_id", invite.RoomID),
+			zap.String("invite_id", invite.ID.Hex()),
+			zap.String("reason", reason),
+			zap.String("ip", c.RealIP()),
+		)
+		if errors.Is(err, mongodb.ErrBanned) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "이 채팅방에서 차단되었습니다"})
+		}
+		if errors.Is(err, mongodb.ErrRoomArchived) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": archivedJoinError})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "채팅방 참여에 실패했습니다"})
+	}
+
+	logger.AuditLog("room_joined", username,
+		zap.String("room_id", invite.RoomID),
+		zap.String("invite_id", invite.ID.Hex()),
+		zap.String("invited_by", invite.CreatedBy),
+		zap.String("ip", c.RealIP()),
+	)
+
+	room.Members = append(room.Members, username)
+	resp := roomToResponse(*room, RoomMgr.GetOnlineMembers(invite.RoomID))
+	resp.MyRole = mongodb.RoomRoleMember
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAcceptInviteHandler_MalformedToken verifies malformed tokens are
+// rejected as invalid before any database access.
+func TestAcceptInviteHandler_MalformedToken(t *testing.T) {
+	for _, token := range []string{"", "short", strings.Repeat("Z", 32), strings.Repeat("a", 33)} {
+		e := newTestEcho()
+		req := httptest.NewRequest(http.MethodPost, "/invites/x/accept", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("token")
+		c.SetParamValues(token)
+		c.Set("username", "alice")
+
+		err := AcceptInviteHandler(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code, "token %q", token)
+	}
+}
+
+func TestAcceptInviteHandler_Unauthenticated(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/invites/x/accept", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("token")
+	c.SetParamValues(strings.Repeat("a", 32))
+
+	err := AcceptInviteHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// TestCreateInviteHandler_InvalidLimits verifies expiry and use limits are
+// validated before any database access.
+func TestCreateInviteHandler_InvalidLimits(t *testing.T) {
+	bodies := []string{
+		`{"expires_in":-1}`,
+		`{"expires_in":2592001}`,
+		`{"max_uses":-1}`,
+		`{"max_uses":1001}`,
+	}
+	for _, body := range bodies {
+		e := newTestEcho()
+		req := httptest.NewRequest(http.MethodPost, "/rooms//invites", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("")
+		c.Set("username", "alice")
+
+		err := CreateInviteHandler(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+}
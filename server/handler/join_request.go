@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.uber.org/zap"
+)
+
+// requestToJoin files a join request for an approval-policy room and tells
+// the room's moderators about it wherever they are connected.
+func requestToJoin(c echo.Context, room mongodb.Room, username string) error {
+	roomID := room.ID.Hex()
+	req, err := mongodb.CreateJoinRequest(roomID, username)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "참여 요청에 실패했습니다"})
+	}
+
+	logger.AuditLog("room_join_requested", username, zap.String("room_id", roomID), zap.String("request_id", req.ID.Hex()), zap.String("ip", c.RealIP()))
+	RoomMgr.NotifyUsers(room.Moderators(), mongodb.ChatMessage{
+		Event:     "JOIN_REQUEST",
+		User:      username,
+		RoomID:    roomID,
+		MessageID: req.ID.Hex(),
+		CreatedAt: req.CreatedAt.Format(time.RFC3339),
+	})
+
+	return c.JSON(http.StatusAccepted, req)
+}
+
+// GET /rooms/:id/join-requests
+func ListJoinRequestsHandler(c echo.Context) error {
+	room, err := requireRoomPermission(c, roomActionDecideJoinRequests)
+	if err != nil {
+		return err
+	}
+
+	requests, err := mongodb.FindPendingJoinRequests(room.ID.Hex())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "참여 요청 조회에 실패했습니다"})
+	}
+	return c.JSON(http.StatusOK, requests)
+}
+
+// decideJoinRequest approves or rejects the ":requestId" request of the ":id"
+// room and notifies the requester over any connected socket.
+func decideJoinRequest(c echo.Context, status string) error {
+	room, err := requireRoomPermission(c, roomActionDecideJoinRequests)
+	if err != nil {
+		return err
+	}
+	roomID := room.ID.Hex()
+	moderator := GetUsername(c)
+
+	req, err := mongodb.DecideJoinRequest(roomID, c.Param("requestId"), status, moderator)
+	if err != nil {
+		if errors.Is(err, mongodb.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "대기 중인 참여 요청을 찾을 수 없습니다"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "참여 요청 처리에 실패했습니다"})
+	}
+
+	if status == mongodb.JoinRequestApproved {
+		if err := mongodb.JoinRoom(roomID, req.Username); err != nil {
+			var refusal string
+			switch {
+			case errors.Is(err, mongodb.ErrBanned):
+				refusal = "차단된 사용자는 승인할 수 없습니다"
+			case errors.Is(err, mongodb.ErrRoomArchived):
+				refusal = archivedJoinError
+			default:
+				// Keep the request pending so it can be approved once there is room.
+				if reopenErr := mongodb.ReopenJoinRequest(req.ID); reopenErr != nil {
+					logger.Logger.Warnw("ReopenJoinRequest failed", "request_id", req.ID.Hex(), "error", reopenErr)
+				}
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "채팅방 참여에 실패했습니다"})
+			}
+			// This request can never get the user in, so it ends up rejected.
+			if rejectErr := mongodb.RejectJoinRequest(req.ID); rejectErr != nil {
+				logger.Logger.Warnw("RejectJoinRequest failed", "request_id", req.ID.Hex(), "error", rejectErr)
+			}
+			req.Status = mongodb.JoinRequestRejected
+			announceJoinDecision(c, roomID, moderator, req)
+			return c.JSON(http.StatusForbidden, map[string]string{"error": refusal})
+		}
+	}
+
+	announceJoinDecision(c, roomID, moderator, req)
+	return c.JSON(http.StatusOK, req)
+}
+
+// announceJoinDecision audits a decided request and tells the requester.
+func announceJoinDecision(c echo.Context, roomID, moderator string, req *mongodb.JoinRequest) {
+	logger.AuditLog("room_join_request_decided", moderator,
+		zap.String("room_id", roomID),
+		zap.String("request_id", req.ID.Hex()),
+		zap.String("target", req.Username),
+		zap.String("status", req.Status),
+		zap.String("ip", c.RealIP()),
+	)
+	// Message carries the decision: approved or rejected.
+	RoomMgr.NotifyUsers([]string{req.Username}, mongodb.ChatMessage{
+		Event:     "JOIN_DECISION",
+		User:      moderator,
+		Message:   req.Status,
+		RoomID:    roomID,
+		MessageID: req.ID.Hex(),
+		CreatedAt: time.Now().Format(time.RFC3339),
+	})
+}
+
+// POST /rooms/:id/join-requests/:requestId/approve
+func ApproveJoinRequestHandler(c echo.Context) error {
+	return decideJoinRequest(c, mongodb.JoinRequestApproved)
+}
+
+// POST /rooms/:id/join-requests/:requestId/reject
+func RejectJoinRequestHandler(c echo.Context) error {
+	return decideJoinRequest(c, mongodb.JoinRequestRejected)
+}
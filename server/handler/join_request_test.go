@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+// TestEffectiveJoinPolicy verifies rooms without a stored policy keep their
+// pre-policy behaviour.
+func TestEffectiveJoinPolicy(t *testing.T) {
+	assert.Equal(t, mongodb.JoinPolicyOpen, mongodb.Room{IsPublic: true}.EffectiveJoinPolicy())
+	assert.Equal(t, mongodb.JoinPolicyOpen, mongodb.Room{IsPublic: true, Password: "hash"}.EffectiveJoinPolicy())
+	assert.Equal(t, mongodb.JoinPolicyOpen, mongodb.Room{IsPublic: false}.EffectiveJoinPolicy())
+	assert.Equal(t, mongodb.JoinPolicyPassword, mongodb.Room{IsPublic: false, Password: "hash"}.EffectiveJoinPolicy())
+	assert.Equal(t, mongodb.JoinPolicyApproval, mongodb.Room{JoinPolicy: mongodb.JoinPolicyApproval}.EffectiveJoinPolicy())
+}
+
+func TestRoom_Moderators(t *testing.T) {
+	room := mongodb.Room{
+		CreatedBy: "olivia",
+		Members:   []string{"olivia", "mike", "mary"},
+		Roles:     map[string]string{"mike": mongodb.RoomRoleModerator},
+	}
+	assert.Equal(t, []string{"olivia", "mike"}, room.Moderators())
+}
+
+func TestJoinEvents_ServerOnly(t *testing.T) {
+	for _, event := range []string{"JOIN_REQUEST", "JOIN_DECISION"} {
+		assert.True(t, isAllowedEvent(event), event)
+		assert.False(t, clientEvents[event], event)
+	}
+}
+
+// TestApproveJoinRequestHandler_InvalidRoom verifies an unknown room returns 404
+// before any database access.
+func TestApproveJoinRequestHandler_InvalidRoom(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/rooms/bad/join-requests/x/approve", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "requestId")
+	c.SetParamValues("bad", "x")
+	c.Set("username", "olivia")
+
+	err := ApproveJoinRequestHandler(c)
+	he, ok := err.(*echo.HTTPError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusNotFound, he.Code)
+	}
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	redisclient "github.com/woonglife62/woongkie-talkie/pkg/redis"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxMentionsPerMessage caps the named @username mentions honoured per message.
+	maxMentionsPerMessage = 20
+	// mentionPreviewLen is the number of characters kept in a mention preview.
+	mentionPreviewLen = 100
+)
+
+// mentionPattern matches @name at the start of the text or after a character
+// that cannot be part of a username, so e-mail addresses are not mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^a-zA-Z0-9_\-@])@([a-zA-Z0-9_-]{3,30})`)
+
+// parseMentions extracts @username, @here and @room from text.
+// Returns nil when the text mentions nobody.
+func parseMentions(text string) *mongodb.MessageMentions {
+	// Messages are HTML-escaped before parsing; mentions never contain entities.
+	text = html.UnescapeString(text)
+
+	var m mongodb.MessageMentions
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := match[1]
+		switch name {
+		case "here":
+			m.Here = true
+		case "room":
+			m.Room = true
+		default:
+			if !seen[name] && len(m.Users) < maxMentionsPerMessage {
+				seen[name] = true
+				m.Users = append(m.Users, name)
+			}
+		}
+	}
+	if len(m.Users) == 0 && !m.Here && !m.Room {
+		return nil
+	}
+	return &m
+}
+
+// mentionTargets resolves who a message notifies, keyed by username with the
+// most specific mention kind. The sender is never notified; named users must
+// be able to see the room.
+func mentionTargets(room mongodb.Room, sender string, m *mongodb.MessageMentions) map[string]string {
+	targets := make(map[string]string)
+	add := func(username, kind string) {
+		if username == sender {
+			return
+		}
+		if _, ok := targets[username]; !ok {
+			targets[username] = kind
+		}
+	}
+
+	if len(m.Users) > 0 {
+		names := m.Users
+		if !room.IsPublic && !room.IsDefault {
+			names = names[:0:0]
+			for _, u := range m.Users {
+				if isRoomMember(room, u) {
+					names = append(names, u)
+				}
+			}
+		}
+		existing, err := mongodb.FindExistingUsernames(names)
+		if err != nil {
+			logger.Logger.Warnw("mention user lookup failed", "room_id", room.ID.Hex(), "error", err)
+		}
+		for _, u := range existing {
+			add(u, mongodb.MentionKindUser)
+		}
+	}
+	if m.Here {
+		for _, u := range onlineRoomMembers(room.ID.Hex()) {
+			add(u, mongodb.MentionKindHere)
+		}
+	}
+	if m.Room {
+		for _, u := range room.Members {
+			add(u, mongodb.MentionKindRoom)
+		}
+	}
+	return targets
+}
+
+// onlineRoomMembers returns users online in roomID on any instance, falling
+// back to this instance's hub when Redis is unavailable.
+func onlineRoomMembers(roomID string) []string {
+	if redisclient.IsAvailable() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if users, err := redisclient.GetOnlineUsers(ctx, roomID); err == nil {
+			return users
+		}
+	}
+	return RoomMgr.GetOnlineMembers(roomID)
+}
+
+// notifyMentions stores an inbox entry for every user msg mentions and sends
+// them a MENTION event wherever they are connected. msg must carry its
+// MessageID, CreatedAt and Mentions.
+func notifyMentions(msg mongodb.ChatMessage) {
+	if msg.Mentions == nil {
+		return
+	}
+	room, err := mongodb.FindRoomByID(msg.RoomID)
+	if err != nil {
+		return
+	}
+	targets := mentionTargets(*room, msg.User, msg.Mentions)
+	if len(targets) == 0 {
+		return
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, msg.CreatedAt)
+	if err != nil {
+		createdAt = time.Now()
+	}
+	preview := []rune(msg.Message)
+	if len(preview) > mentionPreviewLen {
+		preview = preview[:mentionPreviewLen]
+	}
+
+	mentions := make([]mongodb.Mention, 0, len(targets))
+	usernames := make([]string, 0, len(targets))
+	for username, kind := range targets {
+		mentions = append(mentions, mongodb.Mention{
+			Username:  username,
+			RoomID:    msg.RoomID,
+			MessageID: msg.MessageID,
+			From:      msg.User,
+			Kind:      kind,
+			Preview:   string(preview),
+			CreatedAt: createdAt,
+		})
+		usernames = append(usernames, username)
+	}
+	if err := mongodb.InsertMentions(mentions); err != nil {
+		logger.Logger.Warnw("InsertMentions failed", "room_id", msg.RoomID, "message_id", msg.MessageID, "error", err)
+	}
+
+	RoomMgr.NotifyUsers(usernames, mongodb.ChatMessage{
+		Event:     "MENTION",
+		User:      msg.User,
+		Message:   string(preview),
+		RoomID:    msg.RoomID,
+		MessageID: msg.MessageID,
+		CreatedAt: msg.CreatedAt,
+		Mentions:  msg.Mentions,
+	})
+}
+
+type MarkMentionsReadRequest struct {
+	IDs []string `json:"ids"` // empty marks every mention as read
+}
+
+// GET /users/me/mentions?before=RFC3339&before_id=ID&limit=50&unread=true
+// Pass the created_at and id of the last mention received to get the next page.
+func GetMentionsHandler(c echo.Context) error {
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	limit := int64(50)
+	if l, err := strconv.ParseInt(c.QueryParam("limit"), 10, 64); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	var before time.Time
+	if beforeStr := c.QueryParam("before"); beforeStr != "" {
+		t, err := time.Parse(time.RFC3339, beforeStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 시간 형식입니다"})
+		}
+		before = t
+	}
+	var beforeID primitive.ObjectID
+	if idStr := c.QueryParam("before_id"); idStr != "" {
+		id, err := primitive.ObjectIDFromHex(idStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 멘션 ID입니다"})
+		}
+		beforeID = id
+	}
+	unreadOnly := c.QueryParam("unread") == "true"
+
+	mentions, err := mongodb.FindMentions(username, before, beforeID, limit, unreadOnly)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "멘션 조회에 실패했습니다"})
+	}
+	unread, err := mongodb.CountUnreadMentions(username)
+	if err != nil {
+		unread = 0
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"mentions":     mentions,
+		"has_more":     int64(len(mentions)) == limit,
+		"unread_count": unread,
+	})
+}
+
+// POST /users/me/mentions/read
+func MarkMentionsReadHandler(c echo.Context) error {
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	var req MarkMentionsReadRequest
+	if err := c.Bind(&req); err != nil || len(req.IDs) > 100 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+
+	updated, err := mongodb.MarkMentionsRead(username, req.IDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "멘션 읽음 처리에 실패했습니다"})
+	}
+	return c.JSON(http.StatusOK, map[string]int64{"updated": updated})
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+// TestMentionEvent_ServerOnly verifies MENTION crosses Redis but cannot be sent by clients.
+func TestMentionEvent_ServerOnly(t *testing.T) {
+	assert.True(t, isAllowedEvent("MENTION"))
+	assert.False(t, clientEvents["MENTION"])
+}
+
+func TestParseMentions(t *testing.T) {
+	assert.Nil(t, parseMentions("hello world"))
+	assert.Nil(t, parseMentions("mail me at bob@example.com"))
+	assert.Nil(t, parseMentions("@ab is too short"))
+
+	m := parseMentions("@alice and @bob, also @alice again")
+	if assert.NotNil(t, m) {
+		assert.Equal(t, []string{"alice", "bob"}, m.Users)
+		assert.False(t, m.Here)
+		assert.False(t, m.Room)
+	}
+
+	m = parseMentions("heads up @here (@room)")
+	if assert.NotNil(t, m) {
+		assert.Empty(t, m.Users)
+		assert.True(t, m.Here)
+		assert.True(t, m.Room)
+	}
+
+	// Messages are HTML-escaped before parsing.
+	m = parseMentions("&lt;@carol&gt;")
+	if assert.NotNil(t, m) {
+		assert.Equal(t, []string{"carol"}, m.Users)
+	}
+}
+
+func TestParseMentions_CapsNamedUsers(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < maxMentionsPerMessage+5; i++ {
+		b.WriteString("@user")
+		b.WriteString(strings.Repeat("x", i+1))
+		b.WriteString(" ")
+	}
+	m := parseMentions(b.String())
+	if assert.NotNil(t, m) {
+		assert.Len(t, m.Users, maxMentionsPerMessage)
+	}
+}
+
+// TestMentionTargets_RoomAndSender verifies @room reaches every member except the sender.
+func TestMentionTargets_RoomAndSender(t *testing.T) {
+	room := mongodb.Room{Members: []string{"alice", "bob", "carol"}}
+	targets := mentionTargets(room, "alice", &mongodb.MessageMentions{Room: true})
+	assert.Equal(t, map[string]string{
+		"bob":   mongodb.MentionKindRoom,
+		"carol": mongodb.MentionKindRoom,
+	}, targets)
+}
+
+// TestDeliverToUsers_AcrossRooms verifies user notifications reach the user's
+// connections in any room, and nobody else.
+func TestDeliverToUsers_AcrossRooms(t *testing.T) {
+	rm := newRoomManager()
+	hubA := rm.GetOrCreateHub("room-a")
+	hubB := rm.GetOrCreateHub("room-b")
+
+	alice := &Client{Username: "alice", RoomID: "room-b", Send: make(chan mongodb.ChatMessage, 1)}
+	bob := &Client{Username: "bob", RoomID: "room-a", Send: make(chan mongodb.ChatMessage, 1)}
+	hubB.mu.Lock()
+	hubB.Clients[alice] = true
+	hubB.mu.Unlock()
+	hubA.mu.Lock()
+	hubA.Clients[bob] = true
+	hubA.mu.Unlock()
+
+	rm.deliverToUsers([]string{"alice"}, mongodb.ChatMessage{Event: "MENTION", RoomID: "room-a", User: "bob"})
+
+	select {
+	case got := <-alice.Send:
+		assert.Equal(t, "MENTION", got.Event)
+		assert.Equal(t, "room-a", got.RoomID)
+	default:
+		t.Fatal("alice did not receive the mention")
+	}
+	assert.Len(t, bob.Send, 0)
+}
+
+// TestGetMentionsHandler_InvalidBefore verifies a malformed cursor returns 400
+// before any database access.
+func TestGetMentionsHandler_InvalidBefore(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodGet, "/users/me/mentions?before=yesterday", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("username", "alice")
+
+	err := GetMentionsHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetMentionsHandler_InvalidBeforeID(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodGet, "/users/me/mentions?before=2024-01-01T00:00:00Z&before_id=nope", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("username", "alice")
+
+	err := GetMentionsHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestMarkMentionsReadHandler_Unauthenticated(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/users/me/mentions/read", strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := MarkMentionsReadHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
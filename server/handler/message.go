@@ -17,8 +17,9 @@ type EditMessageRequest struct {
 }
 
 type ReplyMessageRequest struct {
-	Message string `json:"message"`
-	ReplyTo string `json:"reply_to"`
+	Message   string `json:"message"`
+	ReplyTo   string `json:"reply_to"`
+	ExpiresIn int64  `json:"expires_in"` // seconds; 0 uses the room's timer, if any
 }
 
 // PUT /rooms/:id/messages/:msgId
@@ -51,6 +52,8 @@ func EditMessageHandler(c echo.Context) error {
 			return c.JSON(http.StatusForbidden, map[string]string{"error": "수정 권한이 없습니다"})
 		case errors.Is(err, mongodb.ErrEditWindowExpired):
 			return c.JSON(http.StatusForbidden, map[string]string{"error": "5분이 지나 수정할 수 없습니다"})
+		case errors.Is(err, mongodb.ErrEditConflict):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "다른 수정과 충돌했습니다. 다시 시도해주세요"})
 		case errors.Is(err, mongodb.ErrMessageDeleted):
 			return c.JSON(http.StatusGone, map[string]string{"error": "삭제된 메시지입니다"})
 		default:
@@ -69,6 +72,7 @@ func EditMessageHandler(c echo.Context) error {
 			Message:   updated.Message,
 			RoomID:    roomID,
 			MessageID: msgID,
+			Revision:  updated.Revision,
 		}:
 		case <-hub.stop:
 		case <-time.After(5 * time.Second):
@@ -79,6 +83,46 @@ func EditMessageHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, updated)
 }
 
+// GET /rooms/:id/messages/:msgId/revisions
+// Edit history is visible to the message author and room moderators only.
+func GetRevisionsHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	roomID := c.Param("id")
+	msgID := c.Param("msgId")
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	chat, err := mongodb.FindChatByID(msgID)
+	if err != nil || chat.RoomID != roomID {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "메시지를 찾을 수 없습니다"})
+	}
+
+	if chat.User != username {
+		room, err := mongodb.FindRoomByID(roomID)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방을 찾을 수 없습니다"})
+		}
+		if !roomCan(*room, username, roomActionViewRevisions) {
+			logger.AuditLog("message_revisions_forbidden", username, zap.String("msg_id", msgID), zap.String("room_id", roomID))
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "수정 이력 조회 권한이 없습니다"})
+		}
+	}
+
+	revisions := chat.Revisions
+	if revisions == nil {
+		revisions = []mongodb.ChatRevision{}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message_id": msgID,
+		"revision":   chat.Revision,
+		"revisions":  revisions,
+	})
+}
+
 // DELETE /rooms/:id/messages/:msgId
 func DeleteMessageHandler(c echo.Context) error {
 	if err := requireRoomMember(c); err != nil {
@@ -91,7 +135,25 @@ func DeleteMessageHandler(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
 	}
 
-	err := mongodb.DeleteChat(msgID, username)
+	chat, err := mongodb.FindChatByID(msgID)
+	if err != nil || chat.RoomID != roomID {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "메시지를 찾을 수 없습니다"})
+	}
+	// Authors delete their own messages; moderators may delete anyone's.
+	author := username
+	if chat.User != username {
+		room, err := mongodb.FindRoomByID(roomID)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방을 찾을 수 없습니다"})
+		}
+		if !roomCan(*room, username, roomActionDeleteMessages) {
+			logger.AuditLog("message_delete_forbidden", username, zap.String("msg_id", msgID), zap.String("room_id", roomID))
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "삭제 권한이 없습니다"})
+		}
+		author = ""
+	}
+
+	deleted, err := mongodb.DeleteChat(msgID, author)
 	if err != nil {
 		switch {
 		case errors.Is(err, mongodb.ErrForbidden):
@@ -102,7 +164,7 @@ func DeleteMessageHandler(c echo.Context) error {
 		}
 	}
 
-	logger.AuditLog("message_deleted", username, zap.String("msg_id", msgID), zap.String("room_id", roomID))
+	logger.AuditLog("message_deleted", username, zap.String("msg_id", msgID), zap.String("room_id", roomID), zap.String("author", chat.User))
 
 	// Broadcast MSG_DELETE event to room (#208)
 	hub := RoomMgr.GetHub(roomID)
@@ -120,6 +182,23 @@ func DeleteMessageHandler(c echo.Context) error {
 		}
 	}
 
+	// A deleted message cannot stay pinned.
+	if err := unpinMessage(roomID, msgID, username); err != nil && !errors.Is(err, mongodb.ErrNotFound) {
+		logger.Logger.Warnw("DeleteMessage: unpin failed", "msg_id", msgID, "error", err)
+	}
+
+	// The mention inbox must not keep showing the deleted text.
+	if err := mongodb.DeleteMessageMentions(msgID); err != nil {
+		logger.Logger.Warnw("DeleteMessage: mention cleanup failed", "msg_id", msgID, "error", err)
+	}
+
+	// Deleting a reply shrinks its parent's thread summary.
+	if deleted.ReplyTo != "" {
+		if parent, err := mongodb.UpdateThreadSummary(deleted.ReplyTo, -1, time.Time{}); err == nil {
+			broadcastThreadUpdate(roomID, parent)
+		}
+	}
+
 	return c.JSON(http.StatusOK, map[string]string{"message": "메시지가 삭제되었습니다"})
 }
 
@@ -153,6 +232,11 @@ func ReplyMessageHandler(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
 	}
 
+	room, err := requireCanSend(c, roomID, username)
+	if err != nil {
+		return err
+	}
+
 	var req ReplyMessageRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
@@ -160,26 +244,38 @@ func ReplyMessageHandler(c echo.Context) error {
 	if req.Message == "" || len([]rune(req.Message)) > 2000 {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "메시지는 1자 이상 2000자 이하이어야 합니다"})
 	}
+	if !validExpiresIn(req.ExpiresIn) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "자동 삭제 시간은 최대 7일까지 지정할 수 있습니다"})
+	}
 	req.Message = html.EscapeString(req.Message)
 
 	// #167: verify the parent message exists and is not deleted
 	parent, err := mongodb.FindChatByID(msgID)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "원본 메시지를 찾을 수 없습니다"})
+		parent = nil
+	}
+	if status, msg := threadParentError(parent, roomID); status != 0 {
+		return c.JSON(status, map[string]string{"error": msg})
 	}
 	if parent.IsDeleted {
 		return c.JSON(http.StatusGone, map[string]string{"error": "삭제된 메시지에는 답장할 수 없습니다"})
 	}
 
 	chatMsg := mongodb.ChatMessage{
-		User:    username,
-		Message: req.Message,
-		RoomID:  roomID,
-		ReplyTo: msgID,
+		User:      username,
+		Message:   req.Message,
+		RoomID:    roomID,
+		ReplyTo:   msgID,
+		Mentions:  parseMentions(req.Message),
+		ExpiresAt: expiresAt(time.Now(), messageTTL(room, req.ExpiresIn)),
 	}
 
+	if err := claimSlowMode(c, *room, username); err != nil {
+		return err
+	}
 	saved, err := mongodb.InsertChatWithReply(chatMsg)
 	if err != nil {
+		releaseSlowMode(*room, username)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "메시지 저장에 실패했습니다"})
 	}
 
@@ -194,12 +290,25 @@ func ReplyMessageHandler(c echo.Context) error {
 			RoomID:    roomID,
 			MessageID: saved.ID.Hex(),
 			ReplyTo:   msgID,
+			Seq:       saved.Seq,
+			Mentions:  saved.Mentions,
+			ExpiresAt: chatMsg.ExpiresAt,
 		}:
 		case <-hub.stop:
 		case <-time.After(5 * time.Second):
 			logger.Logger.Warnw("ReplyMessage: broadcast timed out", "room_id", roomID)
 		}
 	}
+	if saved.Mentions != nil {
+		go notifyMentions(chatToMessage(*saved, "MSG", username))
+	}
+
+	// Keep the parent's reply_count/last_reply_at in sync and notify the room.
+	if updatedParent, err := mongodb.UpdateThreadSummary(msgID, 1, saved.CreatedAt); err != nil {
+		logger.Logger.Warnw("ReplyMessage: thread summary update failed", "msg_id", msgID, "error", err)
+	} else {
+		broadcastThreadUpdate(roomID, updatedParent)
+	}
 
 	return c.JSON(http.StatusCreated, saved)
 }
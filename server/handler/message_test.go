@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+// TestChat_RevisionsHiddenFromJSON verifies that edit history never leaks through
+// regular message responses; it is served only by the revisions endpoint.
+func TestChat_RevisionsHiddenFromJSON(t *testing.T) {
+	chat := mongodb.Chat{
+		Message:  "edited",
+		Revision: 1,
+		Revisions: []mongodb.ChatRevision{
+			{Revision: 0, Message: "original", EditedBy: "alice"},
+			{Revision: 1, Message: "edited", EditedBy: "alice"},
+		},
+	}
+	data, err := json.Marshal(chat)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "original")
+	assert.Contains(t, string(data), `"revision":1`)
+
+	assert.Equal(t, 1, chatToMessage(chat, "CHATLOG", "bob").Revision)
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.uber.org/zap"
+)
+
+// maxPinsPerRoom bounds the pins a room can hold so PINLOG stays small.
+const maxPinsPerRoom = 50
+
+// PinnedMessage is a pin together with the message it points at.
+type PinnedMessage struct {
+	mongodb.RoomPin
+	Message mongodb.Chat `json:"message"`
+}
+
+// pinnedMessages resolves room's pins to their messages in pin order,
+// skipping messages that no longer exist or were deleted.
+func pinnedMessages(room mongodb.Room) ([]PinnedMessage, error) {
+	result := []PinnedMessage{}
+	if len(room.Pins) == 0 {
+		return result, nil
+	}
+	ids := make([]string, len(room.Pins))
+	for i, pin := range room.Pins {
+		ids[i] = pin.MessageID
+	}
+	chats, err := mongodb.FindChatsByIDs(room.ID.Hex(), ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, pin := range room.Pins {
+		chat, ok := chats[pin.MessageID]
+		if !ok || chat.IsDeleted {
+			continue
+		}
+		result = append(result, PinnedMessage{RoomPin: pin, Message: chat})
+	}
+	return result, nil
+}
+
+// pinToMessage converts a pinned message into a wire message of the given event.
+func pinToMessage(p PinnedMessage, event, username string) mongodb.ChatMessage {
+	msg := chatToMessage(p.Message, event, username)
+	msg.PinnedBy = p.PinnedBy
+	msg.PinnedAt = p.PinnedAt.Format(time.RFC3339)
+	return msg
+}
+
+// queuePins puts the room's pinned messages into the client's Send buffer as
+// PINLOG events, right after the CHATLOG history.
+func queuePins(client *Client) {
+	room, err := mongodb.FindRoomByID(client.RoomID)
+	if err != nil {
+		return
+	}
+	pins, err := pinnedMessages(*room)
+	if err != nil {
+		return
+	}
+	for _, p := range pins {
+		select {
+		case client.Send <- pinToMessage(p, "PINLOG", client.Username):
+		default:
+		}
+	}
+}
+
+// GET /rooms/:id/pins
+func GetPinsHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	room, err := mongodb.FindRoomByID(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방을 찾을 수 없습니다"})
+	}
+
+	pins, err := pinnedMessages(*room)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "고정 메시지 조회에 실패했습니다"})
+	}
+	return c.JSON(http.StatusOK, pins)
+}
+
+// requirePinModerator loads the room and verifies the caller may pin in it.
+func requirePinModerator(c echo.Context) (*mongodb.Room, string, error) {
+	if err := requireRoomMember(c); err != nil {
+		return nil, "", err
+	}
+	room, err := requireRoomPermission(c, roomActionManagePins)
+	if err != nil {
+		return nil, "", err
+	}
+	return room, GetUsername(c), nil
+}
+
+// POST /rooms/:id/pins/:msgId
+func PinMessageHandler(c echo.Context) error {
+	room, username, err := requirePinModerator(c)
+	if err != nil {
+		return err
+	}
+	roomID := room.ID.Hex()
+	msgID := c.Param("msgId")
+
+	chat, err := mongodb.FindChatByID(msgID)
+	if err != nil || chat.RoomID != roomID {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "메시지를 찾을 수 없습니다"})
+	}
+	if chat.IsDeleted {
+		return c.JSON(http.StatusGone, map[string]string{"error": "삭제된 메시지는 고정할 수 없습니다"})
+	}
+
+	pin := mongodb.RoomPin{MessageID: msgID, PinnedBy: username, PinnedAt: time.Now()}
+	if _, err := mongodb.AddPin(roomID, pin, maxPinsPerRoom); err != nil {
+		switch {
+		case errors.Is(err, mongodb.ErrAlreadyPinned):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "이미 고정된 메시지입니다"})
+		case errors.Is(err, mongodb.ErrPinLimit):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "고정 메시지는 최대 50개까지 가능합니다"})
+		case errors.Is(err, mongodb.ErrNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방을 찾을 수 없습니다"})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "메시지 고정에 실패했습니다"})
+		}
+	}
+	logger.AuditLog("message_pinned", username, zap.String("msg_id", msgID), zap.String("room_id", roomID))
+
+	pinned := PinnedMessage{RoomPin: pin, Message: *chat}
+	// User stays the message author; PinnedBy names who pinned it.
+	RoomMgr.Broadcast(roomID, pinToMessage(pinned, "PIN_ADD", ""))
+
+	return c.JSON(http.StatusCreated, pinned)
+}
+
+// unpinMessage removes msgID from roomID's pins and broadcasts PIN_REMOVE.
+func unpinMessage(roomID, msgID, username string) error {
+	if err := mongodb.RemovePin(roomID, msgID); err != nil {
+		return err
+	}
+	RoomMgr.Broadcast(roomID, mongodb.ChatMessage{
+		Event:     "PIN_REMOVE",
+		User:      username,
+		RoomID:    roomID,
+		MessageID: msgID,
+	})
+	return nil
+}
+
+// DELETE /rooms/:id/pins/:msgId
+func UnpinMessageHandler(c echo.Context) error {
+	room, username, err := requirePinModerator(c)
+	if err != nil {
+		return err
+	}
+	roomID := room.ID.Hex()
+	msgID := c.Param("msgId")
+
+	if err := unpinMessage(roomID, msgID, username); err != nil {
+		if errors.Is(err, mongodb.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "고정된 메시지가 아닙니다"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "메시지 고정 해제에 실패했습니다"})
+	}
+	logger.AuditLog("message_unpinned", username, zap.String("msg_id", msgID), zap.String("room_id", roomID))
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "메시지 고정이 해제되었습니다"})
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestPinnedMessages_NoPins verifies that a room without pins needs no lookup.
+func TestPinnedMessages_NoPins(t *testing.T) {
+	pins, err := pinnedMessages(mongodb.Room{})
+	assert.NoError(t, err)
+	assert.NotNil(t, pins)
+	assert.Empty(t, pins)
+}
+
+// TestPinToMessage verifies that pin metadata travels with the message author.
+func TestPinToMessage(t *testing.T) {
+	p := PinnedMessage{
+		RoomPin: mongodb.RoomPin{
+			MessageID: "m1",
+			PinnedBy:  "mod",
+			PinnedAt:  time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		},
+		Message: mongodb.Chat{ID: primitive.NewObjectID(), User: "alice", Message: "hi", RoomID: "r1"},
+	}
+
+	msg := pinToMessage(p, "PINLOG", "alice")
+	assert.Equal(t, "PINLOG", msg.Event)
+	assert.Equal(t, "alice", msg.User)
+	assert.Equal(t, "mod", msg.PinnedBy)
+	assert.Equal(t, "2024-05-06T07:08:09Z", msg.PinnedAt)
+	assert.True(t, msg.Owner)
+}
+
+// TestPinEvents_Whitelisted verifies PIN_* are accepted from Redis but not from clients.
+func TestPinEvents_Whitelisted(t *testing.T) {
+	for _, event := range []string{"PIN_ADD", "PIN_REMOVE"} {
+		assert.True(t, isAllowedEvent(event))
+		assert.False(t, clientEvents[event])
+	}
+}
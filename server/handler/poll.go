@@ -0,0 +1,367 @@
+package handler
+
+import (
+	"errors"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxPollQuestionLength is the maximum poll question length in runes.
+	maxPollQuestionLength = 300
+	// maxPollOptionLength is the maximum length of one option in runes.
+	maxPollOptionLength = 100
+	// minPollOptions and maxPollOptions bound the number of options.
+	minPollOptions = 2
+	maxPollOptions = 10
+	// maxPollDuration is how far ahead a poll's close time may be.
+	maxPollDuration = 30 * 24 * time.Hour
+	// pollCloseInterval is how often polls past their close time are closed.
+	pollCloseInterval = 5 * time.Second
+	// pollCloseBatch bounds the polls closed per pass.
+	pollCloseBatch = 200
+	// pollCloseKey is claimed by the instance running a close pass.
+	pollCloseKey = "poll:close"
+)
+
+var (
+	errPollNotFound      = errors.New("poll not found")
+	errPollClosed        = errors.New("poll closed")
+	errInvalidPollOption = errors.New("invalid poll option")
+
+	pollCloseStop chan struct{}
+	pollCloseWg   sync.WaitGroup
+)
+
+// CreatePollRequest is the body of POST /rooms/:id/polls.
+type CreatePollRequest struct {
+	Question  string     `json:"question"`
+	Options   []string   `json:"options"`
+	Multiple  bool       `json:"multiple"`
+	Anonymous bool       `json:"anonymous"`
+	ClosesAt  *time.Time `json:"closes_at"`
+	ExpiresIn int64      `json:"expires_in"` // seconds; 0 uses the room's timer, if any
+}
+
+type VoteRequest struct {
+	Option int `json:"option"`
+}
+
+// PollResponse is a poll with the options the caller voted for.
+type PollResponse struct {
+	MessageID string        `json:"message_id"`
+	Poll      *mongodb.Poll `json:"poll"`
+	Voted     []int         `json:"voted"`
+}
+
+// validatePoll checks req, trimming its options in place. Returns the
+// user-facing error message, or "" when valid.
+func validatePoll(req *CreatePollRequest, now time.Time) string {
+	req.Question = strings.TrimSpace(req.Question)
+	if req.Question == "" || len([]rune(req.Question)) > maxPollQuestionLength {
+		return "질문은 1자 이상 300자 이하이어야 합니다"
+	}
+	if len(req.Options) < minPollOptions || len(req.Options) > maxPollOptions {
+		return "선택지는 2개 이상 10개 이하이어야 합니다"
+	}
+	seen := make(map[string]bool, len(req.Options))
+	for i, opt := range req.Options {
+		opt = strings.TrimSpace(opt)
+		if opt == "" || len([]rune(opt)) > maxPollOptionLength {
+			return "선택지는 1자 이상 100자 이하이어야 합니다"
+		}
+		if seen[opt] {
+			return "같은 선택지를 두 번 넣을 수 없습니다"
+		}
+		seen[opt] = true
+		req.Options[i] = opt
+	}
+	if req.ClosesAt != nil {
+		if !req.ClosesAt.After(now) {
+			return "마감 시간은 현재 이후이어야 합니다"
+		}
+		if req.ClosesAt.Sub(now) > maxPollDuration {
+			return "마감 시간은 최대 30일 후까지 지정할 수 있습니다"
+		}
+	}
+	if !validExpiresIn(req.ExpiresIn) {
+		return "자동 삭제 시간은 최대 7일까지 지정할 수 있습니다"
+	}
+	return ""
+}
+
+// newPoll builds the stored poll for a validated request.
+func newPoll(req CreatePollRequest) *mongodb.Poll {
+	poll := &mongodb.Poll{
+		Question:  html.EscapeString(req.Question),
+		Options:   make([]mongodb.PollOption, len(req.Options)),
+		Multiple:  req.Multiple,
+		Anonymous: req.Anonymous,
+	}
+	for i, opt := range req.Options {
+		poll.Options[i] = mongodb.PollOption{Text: html.EscapeString(opt)}
+	}
+	if req.ClosesAt != nil {
+		closesAt := req.ClosesAt.UTC()
+		poll.ClosesAt = &closesAt
+	}
+	return poll
+}
+
+// createPoll posts a validated poll by username to room and returns the
+// broadcast message.
+func createPoll(room *mongodb.Room, username string, req CreatePollRequest, now time.Time) (mongodb.ChatMessage, error) {
+	roomID := room.ID.Hex()
+	seq, err := mongodb.NextRoomSeq(roomID)
+	if err != nil {
+		return mongodb.ChatMessage{}, err
+	}
+	poll := newPoll(req)
+	msg := mongodb.ChatMessage{
+		Event:     "MSG",
+		User:      username,
+		Message:   poll.Question,
+		RoomID:    roomID,
+		MessageID: primitive.NewObjectID().Hex(),
+		CreatedAt: now.Format("2006-01-02T15:04:05Z07:00"),
+		Seq:       seq,
+		ExpiresAt: expiresAt(now, messageTTL(room, req.ExpiresIn)),
+		Poll:      poll,
+	}
+	stored := msg
+	stored.Event = ""
+	if _, err := mongodb.InsertChat(stored); err != nil {
+		return mongodb.ChatMessage{}, err
+	}
+
+	RoomMgr.Broadcast(roomID, msg)
+	return msg, nil
+}
+
+// broadcastPollUpdate sends the poll's live tallies to every instance. The
+// voter is named only in polls that are not anonymous.
+func broadcastPollUpdate(roomID string, chat *mongodb.Chat, voter string) {
+	user := "system"
+	if voter != "" && !chat.Poll.Anonymous {
+		user = voter
+	}
+	RoomMgr.Broadcast(roomID, mongodb.ChatMessage{
+		Event:     "POLL_UPDATE",
+		User:      user,
+		RoomID:    roomID,
+		MessageID: chat.ID.Hex(),
+		Poll:      chat.Poll,
+	})
+}
+
+// applyVote casts or withdraws username's vote for option on a poll and fans
+// out POLL_UPDATE. Casting a vote already held, or withdrawing one not held,
+// changes nothing.
+func applyVote(roomID, msgID, username string, option int, vote bool) (*mongodb.Chat, error) {
+	chat, err := mongodb.FindChatByID(msgID)
+	if err != nil || chat.RoomID != roomID || chat.IsDeleted || chat.Poll == nil {
+		return nil, errPollNotFound
+	}
+	now := time.Now()
+	if chat.Poll.IsClosed(now) {
+		return nil, errPollClosed
+	}
+	if option < 0 || option >= len(chat.Poll.Options) {
+		return nil, errInvalidPollOption
+	}
+
+	voted := chat.Poll.VotedFor(username)
+	held := false
+	for _, i := range voted {
+		held = held || i == option
+	}
+	if held == vote {
+		return chat, nil
+	}
+
+	var updated *mongodb.Chat
+	if vote {
+		from := -1
+		if !chat.Poll.Multiple && len(voted) > 0 {
+			from = voted[0]
+		}
+		updated, err = mongodb.VotePoll(msgID, roomID, username, option, from, now)
+	} else {
+		updated, err = mongodb.UnvotePoll(msgID, roomID, username, option, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastPollUpdate(roomID, updated, username)
+	return updated, nil
+}
+
+// pollErrorResponse maps applyVote errors to HTTP responses.
+func pollErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, errPollNotFound), errors.Is(err, mongodb.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "투표를 찾을 수 없습니다"})
+	case errors.Is(err, errInvalidPollOption):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 선택지입니다"})
+	case errors.Is(err, errPollClosed):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "마감된 투표입니다"})
+	case errors.Is(err, mongodb.ErrPollConflict):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "투표가 마감되었거나 다른 요청과 충돌했습니다. 다시 시도해주세요"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "투표 처리에 실패했습니다"})
+	}
+}
+
+func pollResponse(chat *mongodb.Chat, username string) PollResponse {
+	return PollResponse{
+		MessageID: chat.ID.Hex(),
+		Poll:      chat.Poll,
+		Voted:     chat.Poll.VotedFor(username),
+	}
+}
+
+// POST /rooms/:id/polls
+func CreatePollHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	roomID := c.Param("id")
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	room, err := requireCanSend(c, roomID, username)
+	if err != nil {
+		return err
+	}
+
+	var req CreatePollRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+	now := time.Now()
+	if msg := validatePoll(&req, now); msg != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+
+	if err := claimSlowMode(c, *room, username); err != nil {
+		return err
+	}
+	msg, err := createPoll(room, username, req, now)
+	if err != nil {
+		releaseSlowMode(*room, username)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "투표 생성에 실패했습니다"})
+	}
+	return c.JSON(http.StatusCreated, msg)
+}
+
+// GET /rooms/:id/messages/:msgId/poll
+func GetPollHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	chat, err := mongodb.FindChatByID(c.Param("msgId"))
+	if err != nil || chat.RoomID != c.Param("id") || chat.IsDeleted || chat.Poll == nil {
+		return pollErrorResponse(c, errPollNotFound)
+	}
+	return c.JSON(http.StatusOK, pollResponse(chat, GetUsername(c)))
+}
+
+// POST /rooms/:id/messages/:msgId/votes
+// In a single-choice poll, voting for another option moves the vote.
+func VotePollHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	var req VoteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+
+	updated, err := applyVote(c.Param("id"), c.Param("msgId"), username, req.Option, true)
+	if err != nil {
+		return pollErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, pollResponse(updated, username))
+}
+
+// DELETE /rooms/:id/messages/:msgId/votes/:option
+func UnvotePollHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	option, err := strconv.Atoi(c.Param("option"))
+	if err != nil {
+		return pollErrorResponse(c, errInvalidPollOption)
+	}
+
+	updated, err := applyVote(c.Param("id"), c.Param("msgId"), username, option, false)
+	if err != nil {
+		return pollErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, pollResponse(updated, username))
+}
+
+// StartPollCloser closes polls as their close time passes, every
+// pollCloseInterval until StopPollCloser is called, and sends each room the
+// final results with POLL_UPDATE.
+func StartPollCloser() {
+	pollCloseStop = make(chan struct{})
+	pollCloseWg.Add(1)
+	go func() {
+		defer pollCloseWg.Done()
+		ticker := time.NewTicker(pollCloseInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if claimOnce(pollCloseKey, pollCloseInterval*9/10) {
+					closeDuePolls(time.Now())
+				}
+			case <-pollCloseStop:
+				return
+			}
+		}
+	}()
+}
+
+// StopPollCloser stops the poll closer and waits for a running pass to finish.
+func StopPollCloser() {
+	if pollCloseStop == nil {
+		return
+	}
+	close(pollCloseStop)
+	pollCloseWg.Wait()
+}
+
+// closeDuePolls closes the polls due at now and announces their results.
+func closeDuePolls(now time.Time) {
+	closed, err := mongodb.CloseDuePolls(now, pollCloseBatch)
+	if err != nil {
+		logger.Logger.Errorw("CloseDuePolls failed", "error", err)
+	}
+	for i := range closed {
+		broadcastPollUpdate(closed[i].RoomID, &closed[i], "")
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+func TestValidatePoll(t *testing.T) {
+	now := time.Now()
+	valid := func() CreatePollRequest {
+		return CreatePollRequest{Question: "Lunch?", Options: []string{" Pizza ", "Sushi"}}
+	}
+
+	req := valid()
+	assert.Empty(t, validatePoll(&req, now))
+	assert.Equal(t, "Pizza", req.Options[0])
+
+	req = valid()
+	req.Options = []string{"Pizza"}
+	assert.NotEmpty(t, validatePoll(&req, now))
+
+	req = valid()
+	req.Options = []string{"Pizza", "Pizza "}
+	assert.NotEmpty(t, validatePoll(&req, now))
+
+	req = valid()
+	req.Question = "  "
+	assert.NotEmpty(t, validatePoll(&req, now))
+
+	past := now.Add(-time.Minute)
+	req = valid()
+	req.ClosesAt = &past
+	assert.NotEmpty(t, validatePoll(&req, now))
+
+	later := now.Add(time.Hour)
+	req = valid()
+	req.ClosesAt = &later
+	assert.Empty(t, validatePoll(&req, now))
+}
+
+// TestPollJSON_Anonymous verifies anonymous polls never reveal voters while
+// still reporting the tallies and voter count.
+func TestPollJSON_Anonymous(t *testing.T) {
+	poll := mongodb.Poll{
+		Question:  "Lunch?",
+		Anonymous: true,
+		Options: []mongodb.PollOption{
+			{Text: "Pizza", Votes: 2, Voters: []string{"olivia", "mike"}},
+			{Text: "Sushi", Votes: 1, Voters: []string{"mike"}},
+		},
+	}
+	data, err := json.Marshal(poll)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "olivia")
+
+	var decoded mongodb.Poll
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 2, decoded.TotalVoters)
+	assert.Equal(t, 2, decoded.Options[0].Votes)
+	assert.False(t, decoded.Closed)
+
+	// Counts survive a second round trip, e.g. through Redis.
+	data, err = json.Marshal(decoded)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 2, decoded.TotalVoters)
+
+	poll.Anonymous = false
+	data, _ = json.Marshal(poll)
+	assert.Contains(t, string(data), "olivia")
+	assert.Equal(t, []int{0, 1}, poll.VotedFor("mike"))
+}
+
+func TestPoll_IsClosed(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Second), now.Add(time.Hour)
+	assert.False(t, mongodb.Poll{}.IsClosed(now))
+	assert.False(t, mongodb.Poll{ClosesAt: &future}.IsClosed(now))
+	assert.True(t, mongodb.Poll{ClosesAt: &past}.IsClosed(now))
+	assert.True(t, mongodb.Poll{ClosedAt: &past}.IsClosed(now))
+}
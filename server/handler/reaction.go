@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/labstack/echo/v4"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+// maxReactionKinds caps the number of distinct emoji on a single message.
+const maxReactionKinds = 20
+
+var errInvalidReaction = errors.New("invalid reaction emoji")
+
+type ReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// ReactionSummary is the aggregated view of one emoji on a message.
+type ReactionSummary struct {
+	Emoji   string   `json:"emoji"`
+	Count   int      `json:"count"`
+	Users   []string `json:"users"`
+	Reacted bool     `json:"reacted"`
+}
+
+// validReactionEmoji reports whether emoji is safe to use as a reaction key.
+// The emoji becomes part of a MongoDB field path ("reactions.<emoji>"), so '.'
+// and '$' are rejected, as are whitespace and HTML-significant characters.
+func validReactionEmoji(emoji string) bool {
+	if emoji == "" || len(emoji) > 64 || len([]rune(emoji)) > 10 {
+		return false
+	}
+	for _, r := range emoji {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(".$<>&\"'", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// summarizeReactions converts a stored reaction map into a stable, count-sorted list.
+func summarizeReactions(reactions map[string][]string, username string) []ReactionSummary {
+	summaries := make([]ReactionSummary, 0, len(reactions))
+	for emoji, users := range reactions {
+		if len(users) == 0 {
+			continue
+		}
+		reacted := false
+		for _, u := range users {
+			if u == username {
+				reacted = true
+				break
+			}
+		}
+		summaries = append(summaries, ReactionSummary{
+			Emoji:   emoji,
+			Count:   len(users),
+			Users:   users,
+			Reacted: reacted,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Count != summaries[j].Count {
+			return summaries[i].Count > summaries[j].Count
+		}
+		return summaries[i].Emoji < summaries[j].Emoji
+	})
+	return summaries
+}
+
+// applyReaction persists a reaction change and fans out REACTION_ADD/REACTION_REMOVE
+// with the message's updated reaction map so every instance sees live counts.
+func applyReaction(roomID, msgID, username, emoji string, add bool) (*mongodb.Chat, error) {
+	if !validReactionEmoji(emoji) {
+		return nil, errInvalidReaction
+	}
+
+	var (
+		updated *mongodb.Chat
+		err     error
+		event   string
+	)
+	if add {
+		updated, err = mongodb.AddReaction(msgID, roomID, username, emoji, maxReactionKinds)
+		event = "REACTION_ADD"
+	} else {
+		updated, err = mongodb.RemoveReaction(msgID, roomID, username, emoji)
+		event = "REACTION_REMOVE"
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	RoomMgr.Broadcast(roomID, mongodb.ChatMessage{
+		Event:     event,
+		User:      username,
+		RoomID:    roomID,
+		MessageID: msgID,
+		Emoji:     emoji,
+		Reactions: updated.Reactions,
+	})
+	return updated, nil
+}
+
+// reactionErrorResponse maps applyReaction errors to HTTP responses.
+func reactionErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, errInvalidReaction):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "사용할 수 없는 이모지입니다"})
+	case errors.Is(err, mongodb.ErrReactionLimit):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "더 이상 새로운 이모지를 추가할 수 없습니다"})
+	case errors.Is(err, mongodb.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "메시지를 찾을 수 없습니다"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "리액션 처리에 실패했습니다"})
+	}
+}
+
+// GET /rooms/:id/messages/:msgId/reactions
+func GetReactionsHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	roomID := c.Param("id")
+	msgID := c.Param("msgId")
+
+	chat, err := mongodb.FindChatByID(msgID)
+	if err != nil || chat.RoomID != roomID || chat.IsDeleted {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "메시지를 찾을 수 없습니다"})
+	}
+	return c.JSON(http.StatusOK, summarizeReactions(chat.Reactions, GetUsername(c)))
+}
+
+// POST /rooms/:id/messages/:msgId/reactions
+func AddReactionHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	var req ReactionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+
+	updated, err := applyReaction(c.Param("id"), c.Param("msgId"), username, req.Emoji, true)
+	if err != nil {
+		return reactionErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, summarizeReactions(updated.Reactions, username))
+}
+
+// DELETE /rooms/:id/messages/:msgId/reactions/:emoji
+func RemoveReactionHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	emoji, err := url.PathUnescape(c.Param("emoji"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "사용할 수 없는 이모지입니다"})
+	}
+
+	updated, err := applyReaction(c.Param("id"), c.Param("msgId"), username, emoji, false)
+	if err != nil {
+		return reactionErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, summarizeReactions(updated.Reactions, username))
+}
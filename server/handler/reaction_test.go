@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidReactionEmoji verifies that only field-path-safe emoji are accepted.
+func TestValidReactionEmoji(t *testing.T) {
+	valid := []string{"👍", "❤️", "🎉", "👨‍👩‍👧", ":+1:"}
+	for _, e := range valid {
+		assert.True(t, validReactionEmoji(e), "expected %q to be valid", e)
+	}
+
+	invalid := []string{"", "a.b", "$set", "<b>", "a b", "\n", strings.Repeat("😀", 11)}
+	for _, e := range invalid {
+		assert.False(t, validReactionEmoji(e), "expected %q to be invalid", e)
+	}
+}
+
+// TestSummarizeReactions verifies counts, ordering and the caller's reacted flag.
+func TestSummarizeReactions(t *testing.T) {
+	reactions := map[string][]string{
+		"👍": {"alice", "bob"},
+		"🎉": {"carol"},
+		"😢": {},
+	}
+
+	got := summarizeReactions(reactions, "bob")
+	if assert.Len(t, got, 2, "empty reaction lists should be skipped") {
+		assert.Equal(t, "👍", got[0].Emoji)
+		assert.Equal(t, 2, got[0].Count)
+		assert.True(t, got[0].Reacted)
+		assert.Equal(t, "🎉", got[1].Emoji)
+		assert.False(t, got[1].Reacted)
+	}
+}
+
+// TestReactionEvents_Whitelisted verifies REACTION_* are accepted from clients and Redis.
+func TestReactionEvents_Whitelisted(t *testing.T) {
+	for _, event := range []string{"REACTION_ADD", "REACTION_REMOVE"} {
+		assert.True(t, clientEvents[event], "%s must be accepted from clients", event)
+		assert.True(t, isAllowedEvent(event), "%s must be accepted from Redis", event)
+	}
+}
+
+// TestAddReactionHandler_InvalidEmoji verifies that an unsafe emoji returns 400
+// before any database access.
+func TestAddReactionHandler_InvalidEmoji(t *testing.T) {
+	e := newTestEcho()
+
+	req := httptest.NewRequest(http.MethodPost, "/rooms//messages/abc/reactions", strings.NewReader(`{"emoji":"$set"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "msgId")
+	c.SetParamValues("", "abc")
+	c.Set("username", "alice")
+
+	err := AddReactionHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
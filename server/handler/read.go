@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/config"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+// unreadCountCap bounds unread counting per room; clients render it as "999+".
+const unreadCountCap = 1000
+
+type MarkReadRequest struct {
+	MessageID string `json:"message_id"`
+}
+
+// markRead advances username's read marker in roomID to msgID and, in rooms small
+// enough for read receipts, tells the other members via a READ event.
+func markRead(roomID, username, msgID string) error {
+	chat, err := mongodb.FindChatByID(msgID)
+	if err != nil || chat.RoomID != roomID {
+		return mongodb.ErrNotFound
+	}
+
+	advanced, err := mongodb.SetReadMarker(username, roomID, msgID, chat.CreatedAt)
+	if err != nil || !advanced {
+		return err
+	}
+	if err := mongodb.MarkRoomMentionsRead(username, roomID, chat.CreatedAt); err != nil {
+		logger.Logger.Warnw("MarkRoomMentionsRead failed", "room_id", roomID, "username", username, "error", err)
+	}
+
+	if config.ReadReceiptMaxMembers > 0 {
+		room, err := mongodb.FindRoomByID(roomID)
+		if err == nil && !room.IsDefault && len(room.Members) <= config.ReadReceiptMaxMembers {
+			RoomMgr.Broadcast(roomID, mongodb.ChatMessage{
+				Event:     "READ",
+				User:      username,
+				RoomID:    roomID,
+				MessageID: msgID,
+				CreatedAt: time.Now().Format(time.RFC3339),
+			})
+		}
+	}
+	return nil
+}
+
+// unreadState returns the unread count and last read message ID for a user whose
+// marker in roomID is marker. A zero marker means the user has never read the room.
+func unreadState(roomID, username string, marker mongodb.ReadMarker) (int, string) {
+	count, err := mongodb.CountUnread(roomID, username, marker.LastReadAt, unreadCountCap)
+	if err != nil {
+		logger.Logger.Warnw("unread count failed", "room_id", roomID, "error", err)
+		count = 0
+	}
+	return int(count), marker.LastReadMessageID
+}
+
+// POST /rooms/:id/read
+func MarkReadHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	roomID := c.Param("id")
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	var req MarkReadRequest
+	if err := c.Bind(&req); err != nil || req.MessageID == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "메시지 ID가 필요합니다"})
+	}
+
+	if err := markRead(roomID, username, req.MessageID); err != nil {
+		if err == mongodb.ErrNotFound {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "메시지를 찾을 수 없습니다"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "읽음 처리에 실패했습니다"})
+	}
+
+	marker, err := mongodb.FindReadMarker(username, roomID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "읽음 처리에 실패했습니다"})
+	}
+	unread, lastRead := unreadState(roomID, username, marker)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"unread_count":         unread,
+		"last_read_message_id": lastRead,
+	})
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+// TestReadEvent_Whitelisted verifies READ is accepted from clients and Redis.
+func TestReadEvent_Whitelisted(t *testing.T) {
+	assert.True(t, clientEvents["READ"])
+	assert.True(t, isAllowedEvent("READ"))
+}
+
+// TestIsRoomMember verifies membership lookup against room.Members.
+func TestIsRoomMember(t *testing.T) {
+	room := mongodb.Room{Members: []string{"alice", "bob"}}
+	assert.True(t, isRoomMember(room, "bob"))
+	assert.False(t, isRoomMember(room, "carol"))
+}
+
+// TestMarkReadHandler_MissingMessageID verifies that an empty message_id returns 400
+// before any database access.
+func TestMarkReadHandler_MissingMessageID(t *testing.T) {
+	e := newTestEcho()
+
+	req := httptest.NewRequest(http.MethodPost, "/rooms//read", strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("")
+	c.Set("username", "alice")
+
+	err := MarkReadHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// TestQueueRead_Coalesces verifies that READ acks arriving within
+// readAckInterval collapse into one pending marker write for the latest ID.
+func TestQueueRead_Coalesces(t *testing.T) {
+	c := &Client{RoomID: "r1", Username: "alice", readAt: time.Now()}
+
+	for _, id := range []string{"m1", "m2", "m3"} {
+		c.queueRead(id)
+	}
+
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	assert.Equal(t, "m3", c.pendingRead)
+	if assert.NotNil(t, c.readTimer) {
+		c.readTimer.Stop()
+	}
+}
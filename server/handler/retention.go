@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/woonglife62/woongkie-talkie/pkg/config"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxRetentionDays caps a room's retention period.
+	maxRetentionDays = 10 * 365
+	// maxRetentionMessages caps a room's kept message count.
+	maxRetentionMessages = 1000000
+	// retentionPurgeKey is claimed by the instance running a purge pass so
+	// replicas do not purge the same rooms at once.
+	retentionPurgeKey = "retention:purge"
+
+	// legalHoldDeleteError is returned when deleting a room under legal hold.
+	legalHoldDeleteError = "법적 보존 조치 중인 채팅방은 삭제할 수 없습니다"
+)
+
+var (
+	retentionStop chan struct{}
+	retentionWg   sync.WaitGroup
+)
+
+// defaultRetention is the server-wide retention policy from config.
+func defaultRetention() mongodb.RetentionPolicy {
+	return mongodb.RetentionPolicy{Days: config.RetentionDays, MaxMessages: config.RetentionMaxMessages}
+}
+
+// validateRetention checks room retention settings. Returns the user-facing
+// error message, or "" when valid.
+func validateRetention(days, maxMessages *int) string {
+	if days != nil && (*days < 0 || *days > maxRetentionDays) {
+		return fmt.Sprintf("보존 기간은 0일 이상 %d일 이하이어야 합니다", maxRetentionDays)
+	}
+	if maxMessages != nil && (*maxMessages < 0 || *maxMessages > maxRetentionMessages) {
+		return fmt.Sprintf("보존 메시지 수는 0개 이상 %d개 이하이어야 합니다", maxRetentionMessages)
+	}
+	return ""
+}
+
+// StartRetentionPurger purges messages and files past their room's retention
+// every config.RetentionPurgeInterval until StopRetentionPurger is called.
+// Call it once MongoDB is initialized.
+func StartRetentionPurger() {
+	retentionStop = make(chan struct{})
+	retentionWg.Add(1)
+	go func() {
+		defer retentionWg.Done()
+		ticker := time.NewTicker(config.RetentionPurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				// Slightly shorter than the interval so the next tick can claim again.
+				if claimOnce(retentionPurgeKey, config.RetentionPurgeInterval*9/10) {
+					purgeExpiredMessages(time.Now())
+				}
+			case <-retentionStop:
+				return
+			}
+		}
+	}()
+}
+
+// StopRetentionPurger stops the purger and waits for a running pass to finish.
+func StopRetentionPurger() {
+	if retentionStop == nil {
+		return
+	}
+	close(retentionStop)
+	retentionWg.Wait()
+}
+
+// purgeExpiredMessages runs one retention pass over every affected room.
+func purgeExpiredMessages(now time.Time) {
+	def := defaultRetention()
+	rooms, err := mongodb.FindRetentionRooms(!def.IsZero())
+	if err != nil {
+		logger.Logger.Errorw("FindRetentionRooms failed", "error", err)
+		return
+	}
+	for _, room := range rooms {
+		policy := room.EffectiveRetention(def)
+		if policy.IsZero() {
+			continue
+		}
+		purgeRoom(room.ID.Hex(), policy, now)
+	}
+}
+
+// purgeRoom deletes roomID's messages and uploads that policy no longer keeps.
+func purgeRoom(roomID string, policy mongodb.RetentionPolicy, now time.Time) {
+	result, err := mongodb.PurgeRoomMessages(roomID, policy, now)
+	if err != nil {
+		logger.Logger.Errorw("PurgeRoomMessages failed", "room_id", roomID, "error", err)
+	}
+	if result != nil {
+		for i := range result.Threads {
+			broadcastThreadUpdate(roomID, &result.Threads[i])
+		}
+	}
+
+	// Uploads have no link to their message; drop those as old as the purged ones.
+	var cutoff time.Time
+	if policy.Days > 0 {
+		cutoff = now.AddDate(0, 0, -policy.Days)
+	}
+	if result != nil && result.PurgedUntil.After(cutoff) {
+		cutoff = result.PurgedUntil
+	}
+	if cutoff.IsZero() {
+		return
+	}
+	files := removeRoomFiles(roomID, cutoff)
+	if result == nil && files == 0 {
+		return
+	}
+
+	var messages, throughSeq int64
+	if result != nil {
+		messages, throughSeq = result.Deleted, result.ThroughSeq
+	}
+	logger.Logger.Infow("retention purge",
+		"room_id", roomID,
+		"messages", messages,
+		"files", files,
+		"through_seq", throughSeq,
+	)
+}
+
+// removeRoomFiles deletes the files uploaded to roomID at or before cutoff from
+// disk and their metadata, returning how many were removed. Metadata is kept
+// for files that could not be deleted so the next pass retries them.
+func removeRoomFiles(roomID string, cutoff time.Time) int64 {
+	files, err := mongodb.FindRoomFilesBefore(roomID, cutoff)
+	if err != nil {
+		logger.Logger.Errorw("FindRoomFilesBefore failed", "room_id", roomID, "error", err)
+		return 0
+	}
+	ids := make([]primitive.ObjectID, 0, len(files))
+	for _, file := range files {
+		if err := os.Remove(file.Path); err != nil && !os.IsNotExist(err) {
+			logger.Logger.Warnw("failed to remove purged upload", "room_id", roomID, "path", file.Path, "error", err)
+			continue
+		}
+		ids = append(ids, file.ID)
+	}
+	deleted, err := mongodb.DeleteFileMetadata(ids)
+	if err != nil {
+		logger.Logger.Errorw("DeleteFileMetadata failed", "room_id", roomID, "error", err)
+	}
+	return deleted
+}
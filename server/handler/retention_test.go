@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRetention(t *testing.T) {
+	days, count := 30, 0
+	assert.Empty(t, validateRetention(&days, &count))
+	assert.Empty(t, validateRetention(nil, nil))
+
+	days = maxRetentionDays + 1
+	assert.NotEmpty(t, validateRetention(&days, nil))
+	count = maxRetentionMessages + 1
+	assert.NotEmpty(t, validateRetention(nil, &count))
+}
+
+// TestRoomCan_ManageRetention verifies only the owner may change retention.
+func TestRoomCan_ManageRetention(t *testing.T) {
+	room := roleTestRoom()
+	assert.True(t, roomCan(room, "olivia", roomActionManageRetention))
+	assert.False(t, roomCan(room, "mike", roomActionManageRetention))
+}
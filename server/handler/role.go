@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.uber.org/zap"
+)
+
+type TransferOwnershipRequest struct {
+	Username string `json:"username"`
+}
+
+// requireRoleManagement loads the room for a role change and rejects rooms
+// without roles: the default room and DMs.
+func requireRoleManagement(c echo.Context, action roomAction) (*mongodb.Room, error) {
+	room, err := requireRoomPermission(c, action)
+	if err != nil {
+		return nil, err
+	}
+	if room.IsDefault || room.IsDM() {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "이 채팅방에서는 역할을 변경할 수 없습니다")
+	}
+	return room, nil
+}
+
+// broadcastRoleUpdate tells the room that username now has role.
+func broadcastRoleUpdate(roomID, username, role string) {
+	RoomMgr.Broadcast(roomID, mongodb.ChatMessage{
+		Event:  "ROLE_UPDATE",
+		User:   username,
+		RoomID: roomID,
+		Role:   role,
+	})
+}
+
+// setMemberRole promotes or demotes the ":username" member of the ":id" room.
+func setMemberRole(c echo.Context, role string) error {
+	room, err := requireRoleManagement(c, roomActionManageRoles)
+	if err != nil {
+		return err
+	}
+	roomID := room.ID.Hex()
+	actor := GetUsername(c)
+	target := c.Param("username")
+
+	switch room.RoleOf(target) {
+	case "":
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방 멤버가 아닙니다"})
+	case mongodb.RoomRoleOwner:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "방장의 역할은 변경할 수 없습니다"})
+	case role:
+		return c.JSON(http.StatusOK, map[string]string{"username": target, "role": role})
+	}
+
+	if err := mongodb.SetRoomRole(roomID, target, role); err != nil {
+		if errors.Is(err, mongodb.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방 멤버가 아닙니다"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "역할 변경에 실패했습니다"})
+	}
+
+	logger.AuditLog("room_role_changed", actor,
+		zap.String("room_id", roomID),
+		zap.String("target", target),
+		zap.String("role", role),
+		zap.String("ip", c.RealIP()),
+	)
+	broadcastRoleUpdate(roomID, target, role)
+	return c.JSON(http.StatusOK, map[string]string{"username": target, "role": role})
+}
+
+// POST /rooms/:id/members/:username/promote
+func PromoteMemberHandler(c echo.Context) error {
+	return setMemberRole(c, mongodb.RoomRoleModerator)
+}
+
+// POST /rooms/:id/members/:username/demote
+func DemoteMemberHandler(c echo.Context) error {
+	return setMemberRole(c, mongodb.RoomRoleMember)
+}
+
+// POST /rooms/:id/transfer
+// The previous owner stays in the room as a moderator.
+func TransferOwnershipHandler(c echo.Context) error {
+	var req TransferOwnershipRequest
+	if err := c.Bind(&req); err != nil || req.Username == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "새 방장을 지정해주세요"})
+	}
+
+	room, err := requireRoleManagement(c, roomActionTransferOwnership)
+	if err != nil {
+		return err
+	}
+	roomID := room.ID.Hex()
+	actor := GetUsername(c)
+	previous := room.CreatedBy
+
+	if req.Username == previous {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "이미 방장입니다"})
+	}
+	if room.RoleOf(req.Username) == "" {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방 멤버가 아닙니다"})
+	}
+
+	if err := mongodb.TransferRoomOwnership(roomID, previous, req.Username); err != nil {
+		if errors.Is(err, mongodb.ErrNotFound) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "방장 정보가 변경되었습니다. 다시 시도해주세요"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "방장 위임에 실패했습니다"})
+	}
+
+	logger.AuditLog("room_ownership_transferred", actor,
+		zap.String("room_id", roomID),
+		zap.String("from", previous),
+		zap.String("to", req.Username),
+		zap.String("ip", c.RealIP()),
+	)
+	broadcastRoleUpdate(roomID, req.Username, mongodb.RoomRoleOwner)
+	broadcastRoleUpdate(roomID, previous, mongodb.RoomRoleModerator)
+	return c.JSON(http.StatusOK, map[string]string{"owner": req.Username})
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+func roleTestRoom() mongodb.Room {
+	return mongodb.Room{
+		CreatedBy: "olivia",
+		Members:   []string{"olivia", "mike", "mary"},
+		Roles:     map[string]string{"mike": mongodb.RoomRoleModerator},
+	}
+}
+
+func TestRoom_RoleOf(t *testing.T) {
+	room := roleTestRoom()
+	assert.Equal(t, mongodb.RoomRoleOwner, room.RoleOf("olivia"))
+	assert.Equal(t, mongodb.RoomRoleModerator, room.RoleOf("mike"))
+	assert.Equal(t, mongodb.RoomRoleMember, room.RoleOf("mary"))
+	assert.Equal(t, "", room.RoleOf("nobody"))
+	assert.Equal(t, "", room.RoleOf(""))
+}
+
+// TestRoomCan verifies each role gets exactly the actions at or below it.
+// Users without sufficient role fall through to the admin lookup, which fails
+// without a database.
+func TestRoomCan(t *testing.T) {
+	room := roleTestRoom()
+
+	assert.True(t, roomCan(room, "olivia", roomActionDeleteRoom))
+	assert.True(t, roomCan(room, "olivia", roomActionManagePins))
+
+	assert.True(t, roomCan(room, "mike", roomActionDeleteMessages))
+	assert.True(t, roomCan(room, "mike", roomActionKickMembers))
+	assert.False(t, roomCan(room, "mike", roomActionManageRoles))
+	assert.False(t, roomCan(room, "mike", roomActionTransferOwnership))
+
+	assert.False(t, roomCan(room, "mary", roomActionManagePins))
+	assert.False(t, roomCan(room, "nobody", roomActionViewRevisions))
+	assert.False(t, roomCan(room, "", roomActionViewRevisions))
+}
+
+func TestRoleUpdateEvent_ServerOnly(t *testing.T) {
+	assert.True(t, isAllowedEvent("ROLE_UPDATE"))
+	assert.False(t, clientEvents["ROLE_UPDATE"])
+}
+
+// TestPromoteMemberHandler_InvalidRoom verifies an unknown room returns 404
+// before any database access.
+func TestPromoteMemberHandler_InvalidRoom(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/rooms/bad/members/mary/promote", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "username")
+	c.SetParamValues("bad", "mary")
+	c.Set("username", "olivia")
+
+	err := PromoteMemberHandler(c)
+	he, ok := err.(*echo.HTTPError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusNotFound, he.Code)
+	}
+}
+
+func TestTransferOwnershipHandler_MissingUsername(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/rooms//transfer", strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("")
+	c.Set("username", "olivia")
+
+	err := TransferOwnershipHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -19,6 +19,17 @@ type CreateRoomRequest struct {
 	IsPublic    bool   `json:"is_public"`
 	Password    string `json:"password,omitempty"`
 	MaxMembers  int    `json:"max_members"`
+	JoinPolicy  string `json:"join_policy,omitempty"` // defaults to password for private rooms with a password, else open
+}
+
+// validJoinPolicies lists the join_policy values accepted from clients; empty
+// picks the default.
+var validJoinPolicies = map[string]bool{
+	"":                           true,
+	mongodb.JoinPolicyOpen:       true,
+	mongodb.JoinPolicyPassword:   true,
+	mongodb.JoinPolicyApproval:   true,
+	mongodb.JoinPolicyInviteOnly: true,
 }
 
 type JoinRoomRequest struct {
@@ -28,33 +39,72 @@ type JoinRoomRequest struct {
 // RoomResponse wraps a Room for the API response.
 // #204: Members field is intentionally omitted to prevent leaking membership info.
 type RoomResponse struct {
-	ID          interface{} `json:"id"`
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	IsPublic    bool        `json:"is_public"`
-	MaxMembers  int         `json:"max_members"`
-	CreatedBy   string      `json:"created_by"`
-	CreatedAt   time.Time   `json:"created_at"`
-	IsDefault   bool        `json:"is_default"`
-	MemberCount int         `json:"member_count"`
-	OnlineMembers []string  `json:"online_members"`
-	HasPassword bool        `json:"has_password"`
+	ID                interface{} `json:"id"`
+	Name              string      `json:"name"`
+	Description       string      `json:"description"`
+	IsPublic          bool        `json:"is_public"`
+	MaxMembers        int         `json:"max_members"`
+	CreatedBy         string      `json:"created_by"`
+	CreatedAt         time.Time   `json:"created_at"`
+	IsDefault         bool        `json:"is_default"`
+	MemberCount       int         `json:"member_count"`
+	OnlineMembers     []string    `json:"online_members"`
+	HasPassword       bool        `json:"has_password"`
+	UnreadCount       int         `json:"unread_count"`
+	LastReadMessageID string      `json:"last_read_message_id,omitempty"`
+	Kind              string      `json:"kind,omitempty"`
+	JoinPolicy        string      `json:"join_policy,omitempty"`
+	Participants      []string    `json:"participants,omitempty"` // DMs only
+	MyRole            string      `json:"my_role,omitempty"`      // caller's room role, empty if not a member
+	SlowModeSeconds   int         `json:"slow_mode_seconds"`
+	ReadOnly          bool        `json:"read_only"`         // only moderators and Posters may send
+	Posters           []string    `json:"posters,omitempty"` // posting allow-list of a read-only room
+	Archived          bool        `json:"archived"`
+	ArchivedAt        *time.Time  `json:"archived_at,omitempty"`
+	// The room's own retention overrides; 0 means the server-wide policy.
+	RetentionDays        int        `json:"retention_days,omitempty"`
+	RetentionMaxMessages int        `json:"retention_max_messages,omitempty"`
+	PurgedUntil          *time.Time `json:"purged_until,omitempty"`        // older messages were removed by retention
+	MessageTTLSeconds    int        `json:"message_ttl_seconds,omitempty"` // new messages self-destruct after this
+	// The caller's own organization of the room; only set in GET /rooms.
+	Favorite  bool   `json:"favorite"`
+	Folder    string `json:"folder,omitempty"`
+	SortOrder int    `json:"sort_order,omitempty"`
+	Hidden    bool   `json:"hidden,omitempty"`
 }
 
 func roomToResponse(room mongodb.Room, online []string) RoomResponse {
-	return RoomResponse{
-		ID:            room.ID,
-		Name:          room.Name,
-		Description:   room.Description,
-		IsPublic:      room.IsPublic,
-		MaxMembers:    room.MaxMembers,
-		CreatedBy:     room.CreatedBy,
-		CreatedAt:     room.CreatedAt,
-		IsDefault:     room.IsDefault,
-		MemberCount:   len(room.Members),
-		OnlineMembers: online,
-		HasPassword:   room.Password != "",
+	resp := RoomResponse{
+		ID:                   room.ID,
+		Name:                 room.Name,
+		Description:          room.Description,
+		IsPublic:             room.IsPublic,
+		MaxMembers:           room.MaxMembers,
+		CreatedBy:            room.CreatedBy,
+		CreatedAt:            room.CreatedAt,
+		IsDefault:            room.IsDefault,
+		MemberCount:          len(room.Members),
+		OnlineMembers:        online,
+		HasPassword:          room.Password != "",
+		Kind:                 room.Kind,
+		SlowModeSeconds:      room.SlowModeSeconds,
+		ReadOnly:             room.ReadOnly,
+		Posters:              room.Posters,
+		Archived:             room.Archived,
+		ArchivedAt:           room.ArchivedAt,
+		RetentionDays:        room.RetentionDays,
+		RetentionMaxMessages: room.RetentionMaxMessages,
+		PurgedUntil:          room.PurgedUntil,
+		MessageTTLSeconds:    room.MessageTTLSeconds,
+	}
+	if !room.IsDM() {
+		resp.JoinPolicy = room.EffectiveJoinPolicy()
+	}
+	// A DM is identified by its participants; only members ever see one.
+	if room.IsDM() {
+		resp.Participants = room.Members
 	}
+	return resp
 }
 
 // POST /rooms
@@ -74,6 +124,13 @@ func CreateRoomHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "최대 인원은 0 이상 1000 이하이어야 합니다"})
 	}
 
+	if !validJoinPolicies[req.JoinPolicy] {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 참여 방식입니다"})
+	}
+	if req.JoinPolicy == mongodb.JoinPolicyPassword && req.Password == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "비밀번호 참여 방식에는 비밀번호가 필요합니다"})
+	}
+
 	// #188: XSS prevention - escape room name and description
 	req.Name = html.EscapeString(req.Name)
 	req.Description = html.EscapeString(req.Description)
@@ -94,7 +151,9 @@ func CreateRoomHandler(c echo.Context) error {
 		CreatedBy:   username,
 		IsDefault:   false,
 		Members:     []string{username},
+		JoinPolicy:  req.JoinPolicy,
 	}
+	room.JoinPolicy = room.EffectiveJoinPolicy()
 
 	created, err := mongodb.CreateRoom(room)
 	if err != nil {
@@ -107,26 +166,75 @@ func CreateRoomHandler(c echo.Context) error {
 	}
 
 	logger.AuditLog("room_created", username, zap.String("room_id", created.ID.Hex()), zap.String("room_name", created.Name), zap.String("ip", c.RealIP()))
-	return c.JSON(http.StatusCreated, roomToResponse(*created, []string{username}))
+	resp := roomToResponse(*created, []string{username})
+	resp.MyRole = mongodb.RoomRoleOwner
+	return c.JSON(http.StatusCreated, resp)
 }
 
 // GET /rooms
 // #280: include private rooms where user is a member
 // #204: response omits Members field
+// Archived rooms are only listed with ?include_archived=true and rooms the
+// user hid with ?include_hidden=true. Rooms come in the user's custom order.
 func ListRoomsHandler(c echo.Context) error {
 	username := GetUsername(c)
-	rooms, err := mongodb.FindRooms(username)
+	includeArchived := c.QueryParam("include_archived") == "true"
+	rooms, err := mongodb.FindRooms(username, includeArchived)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "채팅방 목록 조회에 실패했습니다"})
 	}
 
-	response := make([]RoomResponse, 0, len(rooms))
+	return c.JSON(http.StatusOK, roomListResponse(c, rooms, username))
+}
+
+// roomListResponse orders and filters rooms by username's room prefs and
+// builds the list response. Hidden rooms are only kept with ?include_hidden=true.
+func roomListResponse(c echo.Context, rooms []mongodb.Room, username string) []RoomResponse {
+	markers, err := mongodb.FindReadMarkers(username)
+	if err != nil {
+		logger.Logger.Warnw("read markers lookup failed", "username", username, "error", err)
+	}
+	prefs, err := mongodb.FindRoomPrefs(username)
+	if err != nil {
+		logger.Logger.Warnw("room prefs lookup failed", "username", username, "error", err)
+	}
+
+	rooms = organizeRooms(rooms, prefs, c.QueryParam("include_hidden") == "true")
+	response := roomsToResponse(rooms, username, markers)
+	applyRoomPrefs(response, rooms, prefs)
+	return response
+}
+
+// roomsToResponse builds the list response for rooms as seen by username,
+// including unread state from the user's read markers.
+func roomsToResponse(rooms []mongodb.Room, username string, markers map[string]mongodb.ReadMarker) []RoomResponse {
+	// Unread state is only tracked for rooms the user belongs to or has read before.
+	after := make(map[string]time.Time)
 	for _, room := range rooms {
-		online := RoomMgr.GetOnlineMembers(room.ID.Hex())
-		response = append(response, roomToResponse(room, online))
+		roomID := room.ID.Hex()
+		if marker, ok := markers[roomID]; ok || isRoomMember(room, username) {
+			after[roomID] = marker.LastReadAt
+		}
+	}
+	unread, err := mongodb.CountUnreadRooms(username, after, unreadCountCap)
+	if err != nil {
+		logger.Logger.Warnw("unread counts failed", "username", username, "error", err)
+		unread = map[string]int64{}
 	}
 
-	return c.JSON(http.StatusOK, response)
+	response := make([]RoomResponse, 0, len(rooms))
+	for _, room := range rooms {
+		roomID := room.ID.Hex()
+		online := RoomMgr.GetOnlineMembers(roomID)
+		resp := roomToResponse(room, online)
+		resp.MyRole = room.RoleOf(username)
+		if _, ok := after[roomID]; ok {
+			resp.UnreadCount = int(unread[roomID])
+			resp.LastReadMessageID = markers[roomID].LastReadMessageID
+		}
+		response = append(response, resp)
+	}
+	return response
 }
 
 // GET /rooms/:id
@@ -136,9 +244,15 @@ func GetRoomHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방을 찾을 수 없습니다"})
 	}
+	// DMs do not exist for anyone outside them.
+	if room.IsDM() && !isRoomMember(*room, GetUsername(c)) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방을 찾을 수 없습니다"})
+	}
 
 	online := RoomMgr.GetOnlineMembers(id)
-	return c.JSON(http.StatusOK, roomToResponse(*room, online))
+	resp := roomToResponse(*room, online)
+	resp.MyRole = room.RoleOf(GetUsername(c))
+	return c.JSON(http.StatusOK, resp)
 }
 
 // DELETE /rooms/:id
@@ -147,14 +261,26 @@ func DeleteRoomHandler(c echo.Context) error {
 	id := c.Param("id")
 	username := GetUsername(c)
 
-	err := mongodb.DeleteRoom(id, username)
+	room, err := requireRoomPermission(c, roomActionDeleteRoom)
+	if err != nil {
+		if he, ok := err.(*echo.HTTPError); ok && he.Code == http.StatusForbidden {
+			logger.AuditLog("room_delete_forbidden", username, zap.String("room_id", id), zap.String("ip", c.RealIP()))
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "채팅방 삭제 권한이 없습니다"})
+		}
+		return err
+	}
+	// A DM's history belongs to every participant; they may leave or hide it.
+	if room.IsDM() {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "DM은 삭제할 수 없습니다. 나가기나 숨기기를 이용해주세요"})
+	}
+
+	err = mongodb.DeleteRoom(id)
 	if err != nil {
 		if errors.Is(err, mongodb.ErrNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방을 찾을 수 없습니다"})
 		}
-		if errors.Is(err, mongodb.ErrForbidden) {
-			logger.AuditLog("room_delete_forbidden", username, zap.String("room_id", id), zap.String("ip", c.RealIP()))
-			return c.JSON(http.StatusForbidden, map[string]string{"error": "채팅방 삭제 권한이 없습니다"})
+		if errors.Is(err, mongodb.ErrLegalHold) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": legalHoldDeleteError})
 		}
 		logger.AuditLog("room_delete_failed", username, zap.String("room_id", id), zap.String("ip", c.RealIP()))
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "채팅방 삭제에 실패했습니다"})
@@ -176,14 +302,40 @@ func JoinRoomHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방을 찾을 수 없습니다"})
 	}
+	// DM participants are fixed when the DM is opened via /dm.
+	if room.IsDM() {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "DM에는 참여할 수 없습니다"})
+	}
+
+	if isRoomMember(*room, username) {
+		return c.JSON(http.StatusOK, map[string]string{"message": "채팅방에 참여했습니다"})
+	}
+
+	if room.Archived {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": archivedJoinError})
+	}
+	ban, err := mongodb.FindActiveSanction(id, username, mongodb.SanctionBan)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "차단 여부를 확인하지 못했습니다"})
+	}
+	if ban != nil {
+		logger.AuditLog("room_join_failed", username, zap.String("room_id", id), zap.String("reason", "banned"), zap.String("ip", c.RealIP()))
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "이 채팅방에서 차단되었습니다"})
+	}
 
-	// #209/#134: for private rooms with a password, always require and verify the password
-	if !room.IsPublic && room.Password != "" {
+	switch room.EffectiveJoinPolicy() {
+	case mongodb.JoinPolicyInviteOnly:
+		logger.AuditLog("room_join_failed", username, zap.String("room_id", id), zap.String("reason", "invite_only"), zap.String("ip", c.RealIP()))
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "초대 링크로만 참여할 수 있습니다"})
+	case mongodb.JoinPolicyApproval:
+		return requestToJoin(c, *room, username)
+	case mongodb.JoinPolicyPassword:
+		// #209/#134: always require and verify the password
 		var req JoinRoomRequest
 		if err := c.Bind(&req); err != nil {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
 		}
-		// #209: reject empty password attempts for private password-protected rooms
+		// #209: reject empty password attempts for password-protected rooms
 		if req.Password == "" {
 			return c.JSON(http.StatusForbidden, map[string]string{"error": "비밀번호가 필요합니다"})
 		}
@@ -194,6 +346,12 @@ func JoinRoomHandler(c echo.Context) error {
 	}
 
 	err = mongodb.JoinRoom(id, username)
+	if errors.Is(err, mongodb.ErrBanned) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "이 채팅방에서 차단되었습니다"})
+	}
+	if errors.Is(err, mongodb.ErrRoomArchived) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": archivedJoinError})
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "채팅방 참여에 실패했습니다"})
 	}
@@ -218,6 +376,20 @@ func LeaveRoomHandler(c echo.Context) error {
 type MessagesResponse struct {
 	Messages []mongodb.Chat `json:"messages"`
 	HasMore  bool           `json:"has_more"`
+	// Set once retention has removed older messages: everything up to
+	// PurgedThroughSeq, created at or before PurgedUntil, is gone.
+	PurgedThroughSeq int64      `json:"purged_through_seq,omitempty"`
+	PurgedUntil      *time.Time `json:"purged_until,omitempty"`
+}
+
+// withPurgeInfo reports the room's retention purge watermark in resp.
+func withPurgeInfo(resp MessagesResponse, roomID string) MessagesResponse {
+	room, err := mongodb.FindRoomByID(roomID)
+	if err == nil {
+		resp.PurgedThroughSeq = room.PurgedThroughSeq
+		resp.PurgedUntil = room.PurgedUntil
+	}
+	return resp
 }
 
 // GET /rooms/:id/messages
@@ -230,6 +402,7 @@ func GetRoomMessagesHandler(c echo.Context) error {
 
 	beforeStr := c.QueryParam("before")
 	afterStr := c.QueryParam("after")
+	sinceSeqStr := c.QueryParam("since_seq")
 	limitStr := c.QueryParam("limit")
 
 	limit := int64(50)
@@ -239,6 +412,24 @@ func GetRoomMessagesHandler(c echo.Context) error {
 		}
 	}
 
+	if sinceSeqStr != "" {
+		since, err := strconv.ParseInt(sinceSeqStr, 10, 64)
+		if err != nil || since < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 since_seq 값입니다"})
+		}
+		page, err := mongodb.FindChatByRoomSinceSeq(id, since, limit)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "메시지 조회에 실패했습니다"})
+		}
+		chats := make([]mongodb.Chat, 0, len(page))
+		for _, chat := range page {
+			if !chat.IsDeleted {
+				chats = append(chats, chat)
+			}
+		}
+		return c.JSON(http.StatusOK, withPurgeInfo(MessagesResponse{Messages: chats, HasMore: int64(len(page)) == limit}, id))
+	}
+
 	if afterStr != "" {
 		after, err := time.Parse(time.RFC3339, afterStr)
 		if err != nil {
@@ -267,7 +458,7 @@ func GetRoomMessagesHandler(c echo.Context) error {
 			chats = []mongodb.Chat{}
 		}
 		// #251/#160: HasMore is true only if we got exactly limit results (meaning there may be more)
-		return c.JSON(http.StatusOK, MessagesResponse{Messages: chats, HasMore: int64(len(chats)) == limit})
+		return c.JSON(http.StatusOK, withPurgeInfo(MessagesResponse{Messages: chats, HasMore: int64(len(chats)) == limit}, id))
 	}
 
 	chats, err := mongodb.FindChatByRoom(id)
@@ -278,7 +469,7 @@ func GetRoomMessagesHandler(c echo.Context) error {
 		chats = []mongodb.Chat{}
 	}
 	// #251: HasMore true only when we hit the hard limit of 100
-	return c.JSON(http.StatusOK, MessagesResponse{Messages: chats, HasMore: len(chats) >= 100})
+	return c.JSON(http.StatusOK, withPurgeInfo(MessagesResponse{Messages: chats, HasMore: len(chats) >= 100}, id))
 }
 
 // GET /rooms/default
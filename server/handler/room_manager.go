@@ -1,8 +1,13 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
 	"sync"
+	"time"
 
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
 	redisclient "github.com/woonglife62/woongkie-talkie/pkg/redis"
 )
 
@@ -19,11 +24,21 @@ var RoomMgr = &roomManager{
 }
 
 // SetBroker sets the Redis Pub/Sub broker on the room manager.
-// New hubs created after this call will use the broker.
+// New hubs created after this call will use the broker, and the manager
+// subscribes to user-targeted notifications from all instances.
 func (rm *roomManager) SetBroker(broker *redisclient.Broker) {
 	rm.mu.Lock()
-	defer rm.mu.Unlock()
 	rm.broker = broker
+	rm.mu.Unlock()
+
+	if broker != nil {
+		if err := broker.SubscribeUser(rm.handleUserEnvelope); err != nil {
+			logger.Logger.Warnw("Redis user channel subscribe failed, using local delivery", "error", err)
+		}
+		if err := broker.SubscribeControl(rm.handleControl); err != nil {
+			logger.Logger.Warnw("Redis control channel subscribe failed, using local delivery", "error", err)
+		}
+	}
 }
 
 func (rm *roomManager) GetOrCreateHub(roomID string) *Hub {
@@ -44,6 +59,179 @@ func (rm *roomManager) GetHub(roomID string) *Hub {
 	return rm.hubs[roomID]
 }
 
+// Broadcast delivers msg to every client of roomID on all server instances.
+// When this instance has no hub for the room, the message is published to the
+// Redis broker directly so hubs on other instances still receive it.
+func (rm *roomManager) Broadcast(roomID string, msg mongodb.ChatMessage) {
+	if hub := rm.GetHub(roomID); hub != nil {
+		select {
+		case hub.Broadcast <- msg:
+		case <-hub.stop:
+		case <-time.After(hubSendTimeout):
+			logger.Logger.Warnw("broadcast timed out",
+				"room_id", roomID,
+				"event", msg.Event,
+			)
+		}
+		return
+	}
+
+	rm.mu.RLock()
+	broker := rm.broker
+	rm.mu.RUnlock()
+	if broker == nil || broker.IsFallback() {
+		return
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	if err := broker.Publish(context.Background(), roomID, data); err != nil {
+		logger.Logger.Warnw("broadcast publish failed",
+			"room_id", roomID,
+			"event", msg.Event,
+			"error", err,
+		)
+	}
+}
+
+// userEnvelope carries a message addressed to specific users, wherever they
+// are connected.
+type userEnvelope struct {
+	Usernames []string            `json:"usernames"`
+	Message   mongodb.ChatMessage `json:"message"`
+}
+
+// NotifyUsers delivers msg to every connection of the given users in any room,
+// on all server instances.
+func (rm *roomManager) NotifyUsers(usernames []string, msg mongodb.ChatMessage) {
+	if len(usernames) == 0 {
+		return
+	}
+	rm.mu.RLock()
+	broker := rm.broker
+	rm.mu.RUnlock()
+
+	if broker != nil && !broker.IsFallback() {
+		data, err := json.Marshal(userEnvelope{Usernames: usernames, Message: msg})
+		if err == nil {
+			err = broker.PublishUser(context.Background(), data)
+		}
+		if err == nil {
+			// Delivered locally when our own publish comes back.
+			return
+		}
+		logger.Logger.Warnw("user notification publish failed, delivering locally",
+			"event", msg.Event,
+			"error", err,
+		)
+	}
+	rm.deliverToUsers(usernames, msg)
+}
+
+// handleUserEnvelope delivers a user notification received from Redis.
+func (rm *roomManager) handleUserEnvelope(data []byte) {
+	var env userEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		logger.Logger.Warnw("Redis user notification unmarshal failed", "error", err)
+		return
+	}
+	if !isAllowedEvent(env.Message.Event) {
+		logger.Logger.Warnw("Redis user notification rejected: unknown event type", "event", env.Message.Event)
+		return
+	}
+	rm.deliverToUsers(env.Usernames, env.Message)
+}
+
+// deliverToUsers queues msg on every local connection of the given users.
+func (rm *roomManager) deliverToUsers(usernames []string, msg mongodb.ChatMessage) {
+	targets := make(map[string]bool, len(usernames))
+	for _, u := range usernames {
+		targets[u] = true
+	}
+
+	rm.mu.RLock()
+	hubs := make([]*Hub, 0, len(rm.hubs))
+	for _, hub := range rm.hubs {
+		hubs = append(hubs, hub)
+	}
+	rm.mu.RUnlock()
+
+	var clients []*Client
+	for _, hub := range hubs {
+		hub.mu.RLock()
+		for client := range hub.Clients {
+			if targets[client.Username] {
+				clients = append(clients, client)
+			}
+		}
+		hub.mu.RUnlock()
+	}
+
+	msg.Owner = false
+	for _, client := range clients {
+		client.trySend(msg)
+	}
+}
+
+// controlKick closes a user's connections to a room.
+const controlKick = "kick"
+
+// controlMessage is an instruction carried out by every server instance.
+type controlMessage struct {
+	Type     string `json:"type"`
+	RoomID   string `json:"room_id"`
+	Username string `json:"username"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// KickUser closes every connection of username to roomID on all server
+// instances, with reason as the WebSocket close reason.
+func (rm *roomManager) KickUser(roomID, username, reason string) {
+	rm.mu.RLock()
+	broker := rm.broker
+	rm.mu.RUnlock()
+
+	if broker != nil && !broker.IsFallback() {
+		data, err := json.Marshal(controlMessage{Type: controlKick, RoomID: roomID, Username: username, Reason: reason})
+		if err == nil {
+			err = broker.PublishControl(context.Background(), data)
+		}
+		if err == nil {
+			// Carried out locally when our own publish comes back.
+			return
+		}
+		logger.Logger.Warnw("kick publish failed, kicking locally",
+			"room_id", roomID,
+			"username", username,
+			"error", err,
+		)
+	}
+	rm.kickLocal(roomID, username, reason)
+}
+
+// kickLocal closes username's connections to roomID on this instance.
+func (rm *roomManager) kickLocal(roomID, username, reason string) {
+	if hub := rm.GetHub(roomID); hub != nil {
+		hub.KickUser(username, reason)
+	}
+}
+
+// handleControl carries out a control message received from Redis.
+func (rm *roomManager) handleControl(data []byte) {
+	var msg controlMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		logger.Logger.Warnw("Redis control message unmarshal failed", "error", err)
+		return
+	}
+	switch msg.Type {
+	case controlKick:
+		rm.kickLocal(msg.RoomID, msg.Username, msg.Reason)
+	default:
+		logger.Logger.Warnw("Redis control message rejected: unknown type", "type", msg.Type)
+	}
+}
+
 // RemoveHub closes all connections and removes the hub for a room
 func (rm *roomManager) RemoveHub(roomID string) {
 	rm.mu.Lock()
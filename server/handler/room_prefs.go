@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"html"
+	"net/http"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxFolderNameLength is the maximum folder name length in runes.
+	maxFolderNameLength = 50
+	// maxRoomOrderLength caps how many rooms one order request may list.
+	maxRoomOrderLength = 500
+)
+
+// UpdateRoomPrefsRequest changes how the caller organizes a room; omitted
+// fields stay as they are.
+type UpdateRoomPrefsRequest struct {
+	Favorite  *bool   `json:"favorite"`
+	Folder    *string `json:"folder"` // "" removes the room from its folder
+	SortOrder *int    `json:"sort_order"`
+	Hidden    *bool   `json:"hidden"`
+}
+
+// SetRoomOrderRequest lists room IDs in the caller's preferred order.
+type SetRoomOrderRequest struct {
+	RoomIDs []string `json:"room_ids"`
+}
+
+// validateRoomPrefs returns the user-facing error for req, or "" when valid.
+func validateRoomPrefs(req UpdateRoomPrefsRequest) string {
+	if req.Folder != nil && len([]rune(*req.Folder)) > maxFolderNameLength {
+		return "폴더 이름은 50자 이하이어야 합니다"
+	}
+	if req.SortOrder != nil && (*req.SortOrder < 0 || *req.SortOrder > maxRoomOrderLength) {
+		return "잘못된 정렬 순서입니다"
+	}
+	return ""
+}
+
+// organizeRooms drops rooms the user hid, unless includeHidden is set, and
+// moves rooms with a custom position to the front in that order. Other rooms
+// keep their relative order.
+func organizeRooms(rooms []mongodb.Room, prefs map[string]mongodb.RoomPrefs, includeHidden bool) []mongodb.Room {
+	organized := make([]mongodb.Room, 0, len(rooms))
+	for _, room := range rooms {
+		if !includeHidden && prefs[room.ID.Hex()].Hidden {
+			continue
+		}
+		organized = append(organized, room)
+	}
+	sort.SliceStable(organized, func(i, j int) bool {
+		a := prefs[organized[i].ID.Hex()].SortOrder
+		b := prefs[organized[j].ID.Hex()].SortOrder
+		if a == 0 || b == 0 {
+			return a != 0 && b == 0
+		}
+		return a < b
+	})
+	return organized
+}
+
+// applyRoomPrefs copies the user's preferences for rooms[i] onto response[i].
+func applyRoomPrefs(response []RoomResponse, rooms []mongodb.Room, prefs map[string]mongodb.RoomPrefs) {
+	for i, room := range rooms {
+		p := prefs[room.ID.Hex()]
+		response[i].Favorite = p.Favorite
+		response[i].Folder = p.Folder
+		response[i].SortOrder = p.SortOrder
+		response[i].Hidden = p.Hidden
+	}
+}
+
+// PATCH /users/me/rooms/:id
+func UpdateRoomPrefsHandler(c echo.Context) error {
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+	var req UpdateRoomPrefsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+	if msg := validateRoomPrefs(req); msg != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+
+	update := mongodb.RoomPrefsUpdate{
+		Favorite:  req.Favorite,
+		SortOrder: req.SortOrder,
+		Hidden:    req.Hidden,
+	}
+	if req.Folder != nil {
+		folder := html.EscapeString(*req.Folder)
+		update.Folder = &folder
+	}
+	prefs, err := mongodb.SetRoomPrefs(username, c.Param("id"), update)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "채팅방 설정 저장에 실패했습니다"})
+	}
+	return c.JSON(http.StatusOK, prefs)
+}
+
+// roomOrderAccessError rejects a room order naming a room that does not
+// exist (absent from rooms) or that username cannot open.
+func roomOrderAccessError(rooms []mongodb.Room, roomIDs []string, username string) error {
+	if len(rooms) != len(roomIDs) {
+		return echo.NewHTTPError(http.StatusNotFound, "채팅방을 찾을 수 없습니다")
+	}
+	for _, room := range rooms {
+		if err := roomAccessError(room, username); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PUT /users/me/rooms/order
+// Rooms left out of room_ids lose their custom position.
+func SetRoomOrderHandler(c echo.Context) error {
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+	var req SetRoomOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+	if len(req.RoomIDs) > maxRoomOrderLength {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "정렬할 채팅방이 너무 많습니다"})
+	}
+	seen := make(map[string]bool, len(req.RoomIDs))
+	for _, id := range req.RoomIDs {
+		if _, err := primitive.ObjectIDFromHex(id); err != nil || seen[id] {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 채팅방 ID입니다"})
+		}
+		seen[id] = true
+	}
+	rooms, err := mongodb.FindRoomsByIDs(req.RoomIDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "채팅방 정보를 불러오지 못했습니다"})
+	}
+	if err := roomOrderAccessError(rooms, req.RoomIDs, username); err != nil {
+		return err
+	}
+
+	if err := mongodb.SetRoomOrder(username, req.RoomIDs); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "채팅방 순서 저장에 실패했습니다"})
+	}
+	return c.JSON(http.StatusOK, map[string][]string{"room_ids": req.RoomIDs})
+}
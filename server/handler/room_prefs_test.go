@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestOrganizeRooms verifies hidden rooms are dropped and custom positions
+// come first while other rooms keep their order.
+func TestOrganizeRooms(t *testing.T) {
+	a := mongodb.Room{ID: primitive.NewObjectID(), Name: "a"}
+	b := mongodb.Room{ID: primitive.NewObjectID(), Name: "b"}
+	c := mongodb.Room{ID: primitive.NewObjectID(), Name: "c"}
+	d := mongodb.Room{ID: primitive.NewObjectID(), Name: "d"}
+	prefs := map[string]mongodb.RoomPrefs{
+		c.ID.Hex(): {SortOrder: 1},
+		d.ID.Hex(): {SortOrder: 2, Favorite: true, Folder: "work"},
+		b.ID.Hex(): {Hidden: true},
+	}
+
+	names := func(rooms []mongodb.Room) []string {
+		out := []string{}
+		for _, r := range rooms {
+			out = append(out, r.Name)
+		}
+		return out
+	}
+	rooms := []mongodb.Room{a, b, c, d}
+	assert.Equal(t, []string{"c", "d", "a"}, names(organizeRooms(rooms, prefs, false)))
+	assert.Equal(t, []string{"c", "d", "a", "b"}, names(organizeRooms(rooms, prefs, true)))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, names(organizeRooms(rooms, nil, true)))
+
+	organized := organizeRooms(rooms, prefs, false)
+	response := make([]RoomResponse, len(organized))
+	applyRoomPrefs(response, organized, prefs)
+	assert.True(t, response[1].Favorite)
+	assert.Equal(t, "work", response[1].Folder)
+	assert.Equal(t, 2, response[1].SortOrder)
+	assert.False(t, response[2].Favorite)
+}
+
+func TestUpdateRoomPrefsHandler_Validation(t *testing.T) {
+	bodies := []string{
+		`{"folder":"` + strings.Repeat("f", 51) + `"}`,
+		`{"sort_order":-1}`,
+	}
+	for _, body := range bodies {
+		e := newTestEcho()
+		req := httptest.NewRequest(http.MethodPatch, "/users/me/rooms/x", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("x")
+		c.Set("username", "alice")
+
+		err := UpdateRoomPrefsHandler(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+}
+
+func TestSetRoomOrderHandler_Validation(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	bodies := []string{
+		`{"room_ids":["not-an-id"]}`,
+		`{"room_ids":["` + id + `","` + id + `"]}`,
+	}
+	for _, body := range bodies {
+		e := newTestEcho()
+		req := httptest.NewRequest(http.MethodPut, "/users/me/rooms/order", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("username", "alice")
+
+		err := SetRoomOrderHandler(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+}
+
+func TestSetRoomOrderHandler_Unauthenticated(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPut, "/users/me/rooms/order", strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := SetRoomOrderHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// TestRoomOrderAccessError verifies a room order may only name existing rooms
+// the caller can open.
+func TestRoomOrderAccessError(t *testing.T) {
+	mine := mongodb.Room{ID: primitive.NewObjectID(), Members: []string{"alice"}}
+	other := mongodb.Room{ID: primitive.NewObjectID(), Members: []string{"bob"}}
+	open := mongodb.Room{ID: primitive.NewObjectID(), IsPublic: true}
+
+	assert.NoError(t, roomOrderAccessError([]mongodb.Room{mine, open}, []string{mine.ID.Hex(), open.ID.Hex()}, "alice"))
+
+	err := roomOrderAccessError([]mongodb.Room{mine, other}, []string{mine.ID.Hex(), other.ID.Hex()}, "alice")
+	if assert.Error(t, err) {
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	}
+
+	err = roomOrderAccessError([]mongodb.Room{mine}, []string{mine.ID.Hex(), primitive.NewObjectID().Hex()}, "alice")
+	if assert.Error(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+	}
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.uber.org/zap"
+)
+
+// UpdateRoomRequest changes room settings; omitted fields stay as they are.
+type UpdateRoomRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Password    *string `json:"password"` // "" removes the password
+	MaxMembers  *int    `json:"max_members"`
+	IsPublic    *bool   `json:"is_public"`
+	JoinPolicy  *string `json:"join_policy"`
+	// SlowModeSeconds is the minimum time between two messages of one member;
+	// 0 turns slow mode off. Only the owner may change it.
+	SlowModeSeconds *int `json:"slow_mode_seconds"`
+	// ReadOnly turns the room into a broadcast channel where only moderators
+	// and Posters may send.
+	ReadOnly *bool     `json:"read_only"`
+	Posters  *[]string `json:"posters"`
+	// RetentionDays and RetentionMaxMessages override the server-wide
+	// retention policy; 0 falls back to it. Only the owner may change them.
+	RetentionDays        *int `json:"retention_days"`
+	RetentionMaxMessages *int `json:"retention_max_messages"`
+	// MessageTTLSeconds makes new messages self-destruct after this many
+	// seconds; 0 turns it off. Only the owner may change it.
+	MessageTTLSeconds *int `json:"message_ttl_seconds"`
+	// InvalidateInvites revokes every invite link when the password changes.
+	InvalidateInvites bool `json:"invalidate_invites"`
+}
+
+// maxRoomPosters caps the posting allow-list of a read-only room.
+const maxRoomPosters = 100
+
+// validateRoomUpdate applies the room creation rules to the fields being
+// changed. Returns the user-facing error message, or "" when valid.
+func validateRoomUpdate(req UpdateRoomRequest) string {
+	if req.Name != nil && (*req.Name == "" || len(*req.Name) > 50) {
+		return "채팅방 이름은 1자 이상 50자 이하이어야 합니다"
+	}
+	if req.Description != nil && len(*req.Description) > 200 {
+		return "채팅방 설명은 200자 이하이어야 합니다"
+	}
+	if req.MaxMembers != nil && (*req.MaxMembers < 0 || *req.MaxMembers > 1000) {
+		return "최대 인원은 0 이상 1000 이하이어야 합니다"
+	}
+	if req.JoinPolicy != nil && (*req.JoinPolicy == "" || !validJoinPolicies[*req.JoinPolicy]) {
+		return "잘못된 참여 방식입니다"
+	}
+	if req.Posters != nil {
+		if len(*req.Posters) > maxRoomPosters {
+			return fmt.Sprintf("발신 허용 목록은 최대 %d명입니다", maxRoomPosters)
+		}
+		for _, poster := range *req.Posters {
+			if poster == "" || len(poster) > 50 {
+				return "잘못된 사용자 이름입니다"
+			}
+		}
+	}
+	if req.SlowModeSeconds != nil && (*req.SlowModeSeconds < 0 || *req.SlowModeSeconds > maxSlowModeSeconds) {
+		return fmt.Sprintf("슬로우 모드는 0초 이상 %d초 이하이어야 합니다", maxSlowModeSeconds)
+	}
+	if req.MessageTTLSeconds != nil && (*req.MessageTTLSeconds < 0 || *req.MessageTTLSeconds > maxMessageTTLSeconds) {
+		return fmt.Sprintf("자동 삭제 시간은 0초 이상 %d초 이하이어야 합니다", maxMessageTTLSeconds)
+	}
+	return validateRetention(req.RetentionDays, req.RetentionMaxMessages)
+}
+
+// uniqueUsernames returns usernames without duplicates, keeping their order.
+func uniqueUsernames(usernames []string) []string {
+	seen := make(map[string]bool, len(usernames))
+	unique := make([]string, 0, len(usernames))
+	for _, u := range usernames {
+		if !seen[u] {
+			seen[u] = true
+			unique = append(unique, u)
+		}
+	}
+	return unique
+}
+
+// broadcastRoomUpdate sends the room's new public settings to everyone in it
+// as a ROOM_UPDATE event.
+func broadcastRoomUpdate(room mongodb.Room, actor string) {
+	roomID := room.ID.Hex()
+	payload, err := json.Marshal(roomToResponse(room, RoomMgr.GetOnlineMembers(roomID)))
+	if err != nil {
+		logger.Logger.Warnw("ROOM_UPDATE marshal failed", "room_id", roomID, "error", err)
+		return
+	}
+	RoomMgr.Broadcast(roomID, mongodb.ChatMessage{
+		Event:   "ROOM_UPDATE",
+		User:    actor,
+		RoomID:  roomID,
+		Payload: payload,
+	})
+}
+
+// missingJoinPassword reports whether req would leave room with the password
+// join policy but no password.
+func missingJoinPassword(room mongodb.Room, req UpdateRoomRequest) bool {
+	policy := room.EffectiveJoinPolicy()
+	if req.JoinPolicy != nil {
+		policy = *req.JoinPolicy
+	}
+	hasPassword := room.Password != ""
+	if req.Password != nil {
+		hasPassword = *req.Password != ""
+	}
+	return policy == mongodb.JoinPolicyPassword && !hasPassword
+}
+
+// PATCH /rooms/:id
+func UpdateRoomHandler(c echo.Context) error {
+	var req UpdateRoomRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+	if msg := validateRoomUpdate(req); msg != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+
+	room, err := requireRoomPermission(c, roomActionUpdateSettings)
+	if err != nil {
+		return err
+	}
+	if room.IsDM() {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "DM 설정은 변경할 수 없습니다"})
+	}
+	roomID := room.ID.Hex()
+	username := GetUsername(c)
+	if req.SlowModeSeconds != nil && !roomCan(*room, username, roomActionManageSlowMode) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "슬로우 모드는 방장만 변경할 수 있습니다"})
+	}
+	if (req.RetentionDays != nil || req.RetentionMaxMessages != nil || req.MessageTTLSeconds != nil) && !roomCan(*room, username, roomActionManageRetention) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "메시지 보존 정책은 방장만 변경할 수 있습니다"})
+	}
+
+	update := mongodb.RoomSettingsUpdate{
+		MaxMembers:           req.MaxMembers,
+		IsPublic:             req.IsPublic,
+		JoinPolicy:           req.JoinPolicy,
+		SlowMode:             req.SlowModeSeconds,
+		ReadOnly:             req.ReadOnly,
+		RetentionDays:        req.RetentionDays,
+		RetentionMaxMessages: req.RetentionMaxMessages,
+		MessageTTL:           req.MessageTTLSeconds,
+	}
+	changed := []string{}
+	// #188: XSS prevention - escape room name and description
+	if req.Name != nil {
+		name := html.EscapeString(*req.Name)
+		update.Name = &name
+		changed = append(changed, "name")
+	}
+	if req.Description != nil {
+		description := html.EscapeString(*req.Description)
+		update.Description = &description
+		changed = append(changed, "description")
+	}
+	if req.MaxMembers != nil {
+		// #145: MaxMembers=0 → default 100, as on creation
+		if *req.MaxMembers == 0 {
+			defaultMax := 100
+			update.MaxMembers = &defaultMax
+		}
+		changed = append(changed, "max_members")
+	}
+	if req.IsPublic != nil {
+		changed = append(changed, "is_public")
+	}
+	if req.SlowModeSeconds != nil {
+		changed = append(changed, "slow_mode_seconds")
+	}
+	if req.ReadOnly != nil {
+		changed = append(changed, "read_only")
+	}
+	if req.RetentionDays != nil {
+		changed = append(changed, "retention_days")
+	}
+	if req.RetentionMaxMessages != nil {
+		changed = append(changed, "retention_max_messages")
+	}
+	if req.MessageTTLSeconds != nil {
+		changed = append(changed, "message_ttl_seconds")
+	}
+	if req.Posters != nil {
+		posters := uniqueUsernames(*req.Posters)
+		update.Posters = &posters
+		changed = append(changed, "posters")
+	}
+	if req.JoinPolicy != nil {
+		changed = append(changed, "join_policy")
+	} else if room.JoinPolicy == "" {
+		// Pin the current policy so it is not re-derived from the new settings.
+		policy := room.EffectiveJoinPolicy()
+		update.JoinPolicy = &policy
+	}
+
+	if missingJoinPassword(*room, req) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "비밀번호 참여 방식에는 비밀번호가 필요합니다"})
+	}
+	if req.Password != nil {
+		hashed, err := mongodb.HashRoomPassword(*req.Password)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "비밀번호 처리에 실패했습니다"})
+		}
+		update.Password = &hashed
+		changed = append(changed, "password")
+	}
+
+	updated, err := mongodb.UpdateRoomSettings(roomID, update)
+	if err != nil {
+		switch {
+		case errors.Is(err, mongodb.ErrDuplicateRoomName):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "이미 존재하는 채팅방 이름입니다"})
+		case errors.Is(err, mongodb.ErrMaxMembersTooLow):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "최대 인원은 현재 인원보다 적을 수 없습니다"})
+		case errors.Is(err, mongodb.ErrNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방을 찾을 수 없습니다"})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "채팅방 설정 변경에 실패했습니다"})
+		}
+	}
+
+	var revoked int64
+	if req.Password != nil && req.InvalidateInvites {
+		if revoked, err = mongodb.DeleteRoomInvites(roomID); err != nil {
+			logger.Logger.Warnw("DeleteRoomInvites failed", "room_id", roomID, "error", err)
+		}
+	}
+
+	logger.AuditLog("room_updated", username,
+		zap.String("room_id", roomID),
+		zap.Strings("fields", changed),
+		zap.Int64("invites_revoked", revoked),
+		zap.String("ip", c.RealIP()),
+	)
+	broadcastRoomUpdate(*updated, username)
+
+	resp := roomToResponse(*updated, RoomMgr.GetOnlineMembers(roomID))
+	resp.MyRole = updated.RoleOf(username)
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+// TestUpdateRoomHandler_Validation verifies that settings are validated like
+// room creation before any database access.
+func TestUpdateRoomHandler_Validation(t *testing.T) {
+	bodies := map[string]string{
+		`{"name":""}`: "채팅방 이름",
+		`{"name":"` + strings.Repeat("a", 51) + `"}`:         "채팅방 이름",
+		`{"description":"` + strings.Repeat("d", 201) + `"}`: "채팅방 설명",
+		`{"max_members":1001}`:                               "최대 인원",
+		`{"join_policy":"anyone"}`:                           "참여 방식",
+		`{"join_policy":""}`:                                 "참여 방식",
+		`{"slow_mode_seconds":-1}`:                           "슬로우 모드",
+		`{"slow_mode_seconds":21601}`:                        "슬로우 모드",
+		`{"posters":[""]}`:                                   "사용자 이름",
+		`{"retention_days":-1}`:                              "보존 기간",
+		`{"retention_days":3651}`:                            "보존 기간",
+		`{"retention_max_messages":-5}`:                      "보존 메시지 수",
+		`{"message_ttl_seconds":604801}`:                     "자동 삭제",
+	}
+	for body, want := range bodies {
+		e := newTestEcho()
+		req := httptest.NewRequest(http.MethodPatch, "/rooms/x", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("x")
+		c.Set("username", "alice")
+
+		err := UpdateRoomHandler(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		assert.Contains(t, rec.Body.String(), want, body)
+	}
+}
+
+// TestRoomUpdateEvent_PayloadSurvivesRedis verifies ROOM_UPDATE is relayed
+// between instances with its payload intact.
+func TestRoomUpdateEvent_PayloadSurvivesRedis(t *testing.T) {
+	assert.True(t, isAllowedEvent("ROOM_UPDATE"))
+	assert.False(t, clientEvents["ROOM_UPDATE"])
+
+	payload, err := json.Marshal(RoomResponse{Name: "renamed", MaxMembers: 10})
+	assert.NoError(t, err)
+	data, err := json.Marshal(mongodb.ChatMessage{Event: "ROOM_UPDATE", Payload: payload})
+	assert.NoError(t, err)
+
+	var got mongodb.ChatMessage
+	assert.NoError(t, json.Unmarshal(data, &got))
+	var room RoomResponse
+	assert.NoError(t, json.Unmarshal(got.Payload, &room))
+	assert.Equal(t, "renamed", room.Name)
+	assert.Equal(t, 10, room.MaxMembers)
+}
+
+// TestMissingJoinPassword verifies the password policy is checked against the
+// policy the room ends up with, stored or requested.
+func TestMissingJoinPassword(t *testing.T) {
+	empty, secret := "", "secret"
+	password, open := mongodb.JoinPolicyPassword, mongodb.JoinPolicyOpen
+	stored := mongodb.Room{IsPublic: true, JoinPolicy: mongodb.JoinPolicyPassword, Password: "hash"}
+
+	assert.True(t, missingJoinPassword(stored, UpdateRoomRequest{Password: &empty}))
+	assert.False(t, missingJoinPassword(stored, UpdateRoomRequest{Password: &secret}))
+	assert.False(t, missingJoinPassword(stored, UpdateRoomRequest{Password: &empty, JoinPolicy: &open}))
+	assert.False(t, missingJoinPassword(stored, UpdateRoomRequest{}))
+
+	openRoom := mongodb.Room{IsPublic: true}
+	assert.True(t, missingJoinPassword(openRoom, UpdateRoomRequest{JoinPolicy: &password}))
+	assert.False(t, missingJoinPassword(openRoom, UpdateRoomRequest{JoinPolicy: &password, Password: &secret}))
+}
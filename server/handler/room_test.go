@@ -9,6 +9,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
 )
 
 // TestCreateRoomHandler_InvalidJSON verifies that a malformed JSON body returns 400.
@@ -74,3 +75,51 @@ func TestCreateRoomHandler_DescriptionTooLong(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 	assert.Contains(t, rec.Body.String(), "채팅방 설명")
 }
+
+// TestCreateRoomHandler_InvalidJoinPolicy verifies that an unknown join_policy returns 400.
+func TestCreateRoomHandler_InvalidJoinPolicy(t *testing.T) {
+	e := newTestEcho()
+
+	body := `{"name":"valid","is_public":true,"join_policy":"anyone"}`
+	req := httptest.NewRequest(http.MethodPost, "/rooms", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := CreateRoomHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "참여 방식")
+}
+
+// TestCreateRoomHandler_PasswordPolicyWithoutPassword verifies that the password
+// join policy requires a password.
+func TestCreateRoomHandler_PasswordPolicyWithoutPassword(t *testing.T) {
+	e := newTestEcho()
+
+	body := `{"name":"valid","is_public":false,"join_policy":"password"}`
+	req := httptest.NewRequest(http.MethodPost, "/rooms", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := CreateRoomHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "비밀번호")
+}
+
+// TestRoomAccessError_PublicJoinPolicy verifies that a public room whose join
+// policy is not open can only be read by its members.
+func TestRoomAccessError_PublicJoinPolicy(t *testing.T) {
+	for _, policy := range []string{mongodb.JoinPolicyApproval, mongodb.JoinPolicyInviteOnly, mongodb.JoinPolicyPassword} {
+		room := mongodb.Room{IsPublic: true, JoinPolicy: policy, Members: []string{"alice"}}
+		assert.Error(t, roomAccessError(room, "mallory"), "policy %s must require membership", policy)
+		assert.NoError(t, roomAccessError(room, "alice"))
+	}
+
+	open := mongodb.Room{IsPublic: true, JoinPolicy: mongodb.JoinPolicyOpen}
+	assert.NoError(t, roomAccessError(open, "mallory"))
+	legacy := mongodb.Room{IsPublic: true}
+	assert.NoError(t, roomAccessError(legacy, "mallory"), "legacy public rooms stay open")
+}
@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxBanDuration caps timed bans; a duration of 0 bans permanently.
+	maxBanDuration = 365 * 24 * time.Hour
+	// maxMuteDuration caps mutes, which always expire.
+	maxMuteDuration = 30 * 24 * time.Hour
+	// maxSanctionReasonLength is the maximum reason length in runes.
+	maxSanctionReasonLength = 200
+	// muteCheckInterval bounds how long a socket trusts its cached mute state.
+	muteCheckInterval = 5 * time.Second
+
+	// WebSocket close reasons sent to removed members.
+	kickCloseReason = "kicked from room"
+	banCloseReason  = "banned from room"
+)
+
+// sanctionAuditEvents names the audit events for applying and lifting each kind.
+var sanctionAuditEvents = map[string][2]string{
+	mongodb.SanctionBan:  {"room_member_banned", "room_member_unbanned"},
+	mongodb.SanctionMute: {"room_member_muted", "room_member_unmuted"},
+}
+
+// SanctionRequest is the body of a ban or mute. Duration is in seconds.
+type SanctionRequest struct {
+	Duration int64  `json:"duration"`
+	Reason   string `json:"reason"`
+}
+
+// validateSanction checks req for a sanction of kind and returns its expiry,
+// nil for a permanent ban.
+func validateSanction(kind string, req *SanctionRequest, now time.Time) (*time.Time, error) {
+	limit := maxBanDuration
+	if kind == mongodb.SanctionMute {
+		limit = maxMuteDuration
+		if req.Duration <= 0 {
+			return nil, errors.New("뮤트 기간을 지정해주세요")
+		}
+	}
+	if req.Duration < 0 || time.Duration(req.Duration)*time.Second > limit {
+		return nil, fmt.Errorf("기간은 최대 %d일까지 지정할 수 있습니다", int(limit.Hours()/24))
+	}
+	if len([]rune(req.Reason)) > maxSanctionReasonLength {
+		return nil, fmt.Errorf("사유는 %d자 이하이어야 합니다", maxSanctionReasonLength)
+	}
+	req.Reason = html.EscapeString(req.Reason)
+	if req.Duration == 0 {
+		return nil, nil
+	}
+	expires := now.Add(time.Duration(req.Duration) * time.Second)
+	return &expires, nil
+}
+
+// formatRemaining renders a wait time for users, rounded up to the next
+// second below a minute and to the next minute above it.
+func formatRemaining(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%d초", int((d+time.Second-1)/time.Second))
+	}
+	minutes := int((d + time.Minute - 1) / time.Minute)
+	if minutes < 60 {
+		return fmt.Sprintf("%d분", minutes)
+	}
+	return fmt.Sprintf("%d시간 %d분", minutes/60, minutes%60)
+}
+
+// muteWarning is the error shown to a muted user who tries to send.
+func muteWarning(mute *mongodb.RoomSanction, now time.Time) string {
+	if mute.ExpiresAt == nil {
+		return "이 채팅방에서 메시지를 보낼 수 없습니다."
+	}
+	return fmt.Sprintf("뮤트 상태입니다. %s 후에 메시지를 보낼 수 있습니다.", formatRemaining(mute.ExpiresAt.Sub(now)))
+}
+
+// activeMute returns username's current mute in roomID, or nil. Lookup
+// errors fail open so an outage does not silence the whole room.
+func activeMute(roomID, username string) *mongodb.RoomSanction {
+	mute, err := mongodb.FindActiveSanction(roomID, username, mongodb.SanctionMute)
+	if err != nil {
+		logger.Logger.Warnw("FindActiveSanction failed", "room_id", roomID, "username", username, "error", err)
+		return nil
+	}
+	return mute
+}
+
+// requireNotMuted rejects REST sends from a muted user.
+func requireNotMuted(roomID, username string) error {
+	if mute := activeMute(roomID, username); mute != nil {
+		return echo.NewHTTPError(http.StatusForbidden, muteWarning(mute, time.Now()))
+	}
+	return nil
+}
+
+// currentMute returns the client's active mute, re-reading it at most every
+// muteCheckInterval. Only readPump calls it, so the cache needs no lock.
+func (c *Client) currentMute() *mongodb.RoomSanction {
+	now := time.Now()
+	if now.Sub(c.muteCheckedAt) >= muteCheckInterval {
+		c.mute = activeMute(c.RoomID, c.Username)
+		c.muteCheckedAt = now
+	}
+	if c.mute != nil && c.mute.ExpiresAt != nil && !now.Before(*c.mute.ExpiresAt) {
+		c.mute = nil
+	}
+	return c.mute
+}
+
+// requireModerationTarget loads the room and checks the caller may perform
+// action against the ":username" member. DMs have no moderators.
+func requireModerationTarget(c echo.Context, action roomAction) (*mongodb.Room, string, error) {
+	room, err := requireRoomPermission(c, action)
+	if err != nil {
+		return nil, "", err
+	}
+	if room.IsDM() {
+		return nil, "", echo.NewHTTPError(http.StatusBadRequest, "DM에서는 사용할 수 없습니다")
+	}
+	target := c.Param("username")
+	if !roomCanActOn(*room, GetUsername(c), target, action) {
+		if action == roomActionKickMembers {
+			return nil, "", echo.NewHTTPError(http.StatusForbidden, "이 사용자를 내보낼 권한이 없습니다")
+		}
+		return nil, "", echo.NewHTTPError(http.StatusForbidden, "이 사용자를 제재할 권한이 없습니다")
+	}
+	return room, target, nil
+}
+
+// sanctionMember bans or mutes the ":username" member of the ":id" room.
+func sanctionMember(c echo.Context, kind string) error {
+	var req SanctionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+	expiresAt, err := validateSanction(kind, &req, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	room, target, err := requireModerationTarget(c, roomActionSanctionMembers)
+	if err != nil {
+		return err
+	}
+	roomID := room.ID.Hex()
+	actor := GetUsername(c)
+
+	sanction, err := mongodb.SetRoomSanction(mongodb.RoomSanction{
+		RoomID:    roomID,
+		Username:  target,
+		Kind:      kind,
+		Reason:    req.Reason,
+		CreatedBy: actor,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "제재 처리에 실패했습니다"})
+	}
+
+	if kind == mongodb.SanctionBan {
+		if err := mongodb.RemoveRoomMember(roomID, target); err != nil {
+			logger.Logger.Warnw("RemoveRoomMember failed", "room_id", roomID, "username", target, "error", err)
+		}
+		if err := mongodb.RejectPendingJoinRequests(roomID, target, actor); err != nil {
+			logger.Logger.Warnw("RejectPendingJoinRequests failed", "room_id", roomID, "username", target, "error", err)
+		}
+		RoomMgr.KickUser(roomID, target, banCloseReason)
+	}
+
+	fields := []zap.Field{
+		zap.String("room_id", roomID),
+		zap.String("target", target),
+		zap.String("reason", req.Reason),
+		zap.String("ip", c.RealIP()),
+	}
+	if expiresAt != nil {
+		fields = append(fields, zap.Time("expires_at", *expiresAt))
+	}
+	logger.AuditLog(sanctionAuditEvents[kind][0], actor, fields...)
+	return c.JSON(http.StatusOK, sanction)
+}
+
+// liftSanction removes the ":username" member's sanction of kind.
+func liftSanction(c echo.Context, kind string) error {
+	room, target, err := requireModerationTarget(c, roomActionSanctionMembers)
+	if err != nil {
+		return err
+	}
+	roomID := room.ID.Hex()
+
+	if err := mongodb.RemoveRoomSanction(roomID, target, kind); err != nil {
+		if errors.Is(err, mongodb.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "적용 중인 제재가 없습니다"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "제재 해제에 실패했습니다"})
+	}
+
+	logger.AuditLog(sanctionAuditEvents[kind][1], GetUsername(c),
+		zap.String("room_id", roomID),
+		zap.String("target", target),
+		zap.String("ip", c.RealIP()),
+	)
+	return c.JSON(http.StatusOK, map[string]string{"message": "제재가 해제되었습니다"})
+}
+
+// POST /rooms/:id/members/:username/kick
+// Removes the member and closes their connections on every instance. Unlike a
+// ban, they may join again.
+func KickMemberHandler(c echo.Context) error {
+	room, target, err := requireModerationTarget(c, roomActionKickMembers)
+	if err != nil {
+		return err
+	}
+	roomID := room.ID.Hex()
+	if !isRoomMember(*room, target) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "채팅방 멤버가 아닙니다"})
+	}
+
+	if err := mongodb.RemoveRoomMember(roomID, target); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "멤버 내보내기에 실패했습니다"})
+	}
+	RoomMgr.KickUser(roomID, target, kickCloseReason)
+
+	logger.AuditLog("room_member_kicked", GetUsername(c),
+		zap.String("room_id", roomID),
+		zap.String("target", target),
+		zap.String("ip", c.RealIP()),
+	)
+	return c.JSON(http.StatusOK, map[string]string{"message": "멤버를 내보냈습니다"})
+}
+
+// POST /rooms/:id/members/:username/ban
+// Removes the member and bars them from rejoining; duration 0 bans permanently.
+func BanMemberHandler(c echo.Context) error {
+	return sanctionMember(c, mongodb.SanctionBan)
+}
+
+// DELETE /rooms/:id/members/:username/ban
+func UnbanMemberHandler(c echo.Context) error {
+	return liftSanction(c, mongodb.SanctionBan)
+}
+
+// POST /rooms/:id/members/:username/mute
+func MuteMemberHandler(c echo.Context) error {
+	return sanctionMember(c, mongodb.SanctionMute)
+}
+
+// DELETE /rooms/:id/members/:username/mute
+func UnmuteMemberHandler(c echo.Context) error {
+	return liftSanction(c, mongodb.SanctionMute)
+}
+
+// GET /rooms/:id/sanctions
+func ListSanctionsHandler(c echo.Context) error {
+	room, err := requireRoomPermission(c, roomActionSanctionMembers)
+	if err != nil {
+		return err
+	}
+	sanctions, err := mongodb.FindRoomSanctions(room.ID.Hex())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "제재 목록을 불러올 수 없습니다"})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"sanctions": sanctions})
+}
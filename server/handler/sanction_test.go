@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+func TestValidateSanction(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// A ban without a duration is permanent.
+	expires, err := validateSanction(mongodb.SanctionBan, &SanctionRequest{}, now)
+	assert.NoError(t, err)
+	assert.Nil(t, expires)
+
+	expires, err = validateSanction(mongodb.SanctionMute, &SanctionRequest{Duration: 600}, now)
+	if assert.NoError(t, err) && assert.NotNil(t, expires) {
+		assert.Equal(t, now.Add(10*time.Minute), *expires)
+	}
+
+	// Mutes must expire.
+	_, err = validateSanction(mongodb.SanctionMute, &SanctionRequest{}, now)
+	assert.Error(t, err)
+
+	_, err = validateSanction(mongodb.SanctionBan, &SanctionRequest{Duration: -1}, now)
+	assert.Error(t, err)
+	_, err = validateSanction(mongodb.SanctionMute, &SanctionRequest{Duration: int64(maxMuteDuration/time.Second) + 1}, now)
+	assert.Error(t, err)
+
+	_, err = validateSanction(mongodb.SanctionBan, &SanctionRequest{Reason: strings.Repeat("가", maxSanctionReasonLength+1)}, now)
+	assert.Error(t, err)
+
+	req := &SanctionRequest{Reason: "<b>spam</b>"}
+	_, err = validateSanction(mongodb.SanctionBan, req, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "&lt;b&gt;spam&lt;/b&gt;", req.Reason)
+}
+
+func TestFormatRemaining(t *testing.T) {
+	assert.Equal(t, "1초", formatRemaining(200*time.Millisecond))
+	assert.Equal(t, "45초", formatRemaining(45*time.Second))
+	assert.Equal(t, "2분", formatRemaining(61*time.Second))
+	assert.Equal(t, "1시간 30분", formatRemaining(90*time.Minute))
+}
+
+// TestRoomCanActOn verifies sanctions need the action's role and a higher rank
+// than the target, and never reach the owner.
+func TestRoomCanActOn(t *testing.T) {
+	room := roleTestRoom()
+	room.Members = append(room.Members, "max")
+	room.Roles["max"] = mongodb.RoomRoleModerator
+
+	assert.True(t, roomCanActOn(room, "olivia", "mike", roomActionSanctionMembers))
+	assert.True(t, roomCanActOn(room, "mike", "mary", roomActionSanctionMembers))
+	assert.True(t, roomCanActOn(room, "mike", "outsider", roomActionSanctionMembers))
+
+	assert.False(t, roomCanActOn(room, "mike", "max", roomActionSanctionMembers))
+	assert.False(t, roomCanActOn(room, "mike", "olivia", roomActionSanctionMembers))
+	assert.False(t, roomCanActOn(room, "mary", "mike", roomActionSanctionMembers))
+	assert.False(t, roomCanActOn(room, "mike", "mike", roomActionSanctionMembers))
+}
+
+func TestMuteMemberHandler_MissingDuration(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/rooms/bad/members/mary/mute", strings.NewReader(`{"reason":"spam"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "username")
+	c.SetParamValues("bad", "mary")
+	c.Set("username", "mike")
+
+	err := MuteMemberHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestBanMemberHandler_Unauthenticated(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/rooms/bad/members/mary/ban", strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "username")
+	c.SetParamValues("bad", "mary")
+
+	err := BanMemberHandler(c)
+	he, ok := err.(*echo.HTTPError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusUnauthorized, he.Code)
+	}
+}
+
+func TestListSanctionsHandler_InvalidRoom(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodGet, "/rooms/bad/sanctions", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("bad")
+	c.Set("username", "mike")
+
+	err := ListSanctionsHandler(c)
+	he, ok := err.(*echo.HTTPError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusNotFound, he.Code)
+	}
+}
+
+// TestRoomManager_KickUser verifies a kick without Redis closes the user's
+// local connections with a policy-violation frame carrying the reason.
+func TestRoomManager_KickUser(t *testing.T) {
+	hub := RoomMgr.GetOrCreateHub("room-kick")
+	defer RoomMgr.RemoveHub("room-kick")
+
+	client, conn, cleanup := makeClient(t, "mary", "room-kick")
+	defer cleanup()
+	client.Hub = hub
+	hub.Register <- client
+	time.Sleep(50 * time.Millisecond)
+
+	RoomMgr.KickUser("room-kick", "mary", kickCloseReason)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		ce, ok := err.(*websocket.CloseError)
+		if assert.True(t, ok, "expected a close frame, got %v", err) {
+			assert.Equal(t, websocket.ClosePolicyViolation, ce.Code)
+			assert.Equal(t, kickCloseReason, ce.Text)
+		}
+		break
+	}
+
+	hub.mu.RLock()
+	_, stillConnected := hub.Clients[client]
+	hub.mu.RUnlock()
+	assert.False(t, stillConnected)
+}
+
+func TestKickMemberHandler_InvalidRoom(t *testing.T) {
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/rooms/bad/members/mary/kick", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "username")
+	c.SetParamValues("bad", "mary")
+	c.Set("username", "mike")
+
+	err := KickMemberHandler(c)
+	he, ok := err.(*echo.HTTPError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusNotFound, he.Code)
+	}
+}
@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxScheduleAhead is how far in the future a message may be scheduled.
+	maxScheduleAhead = 30 * 24 * time.Hour
+	// maxPendingScheduled caps a user's pending scheduled messages per room.
+	maxPendingScheduled = 20
+	// scheduledPollInterval is how often due scheduled messages are posted.
+	scheduledPollInterval = time.Second
+	// scheduledPollBatch bounds the messages posted per poll.
+	scheduledPollBatch = 100
+	// scheduledClaimTimeout is how long a claimed message may stay unsent
+	// before another server takes it over.
+	scheduledClaimTimeout = time.Minute
+)
+
+// Reasons a due scheduled message was not posted.
+const (
+	skipRoomGone  = "room_not_found"
+	skipArchived  = "room_archived"
+	skipNotMember = "not_member"
+	skipReadOnly  = "read_only"
+	skipMuted     = "muted"
+)
+
+var (
+	scheduledStop chan struct{}
+	scheduledWg   sync.WaitGroup
+)
+
+// Storage used by postScheduled; tests replace it to run the claim takeover
+// paths without MongoDB.
+var (
+	scheduledChatExists    = mongodb.ChatExists
+	scheduledFindRoom      = mongodb.FindRoomByID
+	scheduledNextSeq       = mongodb.NextRoomSeq
+	scheduledInsertChat    = mongodb.InsertChat
+	scheduledFinishMessage = mongodb.FinishScheduledMessage
+)
+
+// ScheduleMessageRequest is the body of POST /rooms/:id/scheduled.
+type ScheduleMessageRequest struct {
+	Message string    `json:"message"`
+	SendAt  time.Time `json:"send_at"`
+}
+
+// UpdateScheduledRequest changes a pending scheduled message; omitted fields
+// stay as they are.
+type UpdateScheduledRequest struct {
+	Message *string    `json:"message"`
+	SendAt  *time.Time `json:"send_at"`
+}
+
+// validateScheduledMessage returns the user-facing error for text, or "" when valid.
+func validateScheduledMessage(text string) string {
+	if text == "" || len([]rune(text)) > 2000 {
+		return "메시지는 1자 이상 2000자 이하이어야 합니다"
+	}
+	return ""
+}
+
+// validateSendAt returns the user-facing error for a send time requested at
+// now, or "" when valid.
+func validateSendAt(sendAt, now time.Time) string {
+	if !sendAt.After(now) {
+		return "예약 시간은 현재 이후이어야 합니다"
+	}
+	if sendAt.Sub(now) > maxScheduleAhead {
+		return fmt.Sprintf("예약 시간은 최대 %d일 후까지 지정할 수 있습니다", int(maxScheduleAhead/(24*time.Hour)))
+	}
+	return ""
+}
+
+// canSchedule reports whether username belongs to room, so messages they
+// schedule there can be posted later. Everyone belongs to the default room.
+func canSchedule(room mongodb.Room, username string) bool {
+	return room.IsDefault || isRoomMember(room, username)
+}
+
+// scheduledSkipReason returns why a scheduled message by username can no
+// longer be posted to room, or "" if it can. Mutes are checked separately.
+func scheduledSkipReason(room mongodb.Room, username string) string {
+	switch {
+	case room.Archived:
+		return skipArchived
+	case !canSchedule(room, username):
+		return skipNotMember
+	case !roomCanPost(room, username):
+		return skipReadOnly
+	}
+	return ""
+}
+
+// requireScheduleRoom loads the ":id" room and rejects callers who could not
+// post a message there.
+func requireScheduleRoom(c echo.Context, username string) (*mongodb.Room, error) {
+	if err := requireRoomMember(c); err != nil {
+		return nil, err
+	}
+	room, err := mongodb.FindRoomByID(c.Param("id"))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "채팅방을 찾을 수 없습니다")
+	}
+	if room.Archived {
+		return nil, echo.NewHTTPError(http.StatusForbidden, archivedWarning)
+	}
+	if !canSchedule(*room, username) {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "채팅방 멤버만 메시지를 예약할 수 있습니다")
+	}
+	if !roomCanPost(*room, username) {
+		return nil, echo.NewHTTPError(http.StatusForbidden, readOnlyWarning)
+	}
+	return room, nil
+}
+
+// POST /rooms/:id/scheduled
+func ScheduleMessageHandler(c echo.Context) error {
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+	roomID := c.Param("id")
+	if _, err := requireScheduleRoom(c, username); err != nil {
+		return err
+	}
+
+	var req ScheduleMessageRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+	if msg := validateScheduledMessage(req.Message); msg != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+	if msg := validateSendAt(req.SendAt, time.Now()); msg != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+
+	pending, err := mongodb.CountPendingScheduledMessages(roomID, username)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "메시지 예약에 실패했습니다"})
+	}
+	if pending >= maxPendingScheduled {
+		return c.JSON(http.StatusConflict, map[string]string{"error": fmt.Sprintf("채팅방당 예약 메시지는 최대 %d개입니다", maxPendingScheduled)})
+	}
+
+	scheduled, err := mongodb.CreateScheduledMessage(mongodb.ScheduledMessage{
+		RoomID:   roomID,
+		Username: username,
+		Message:  html.EscapeString(req.Message),
+		SendAt:   req.SendAt.UTC(),
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "메시지 예약에 실패했습니다"})
+	}
+	return c.JSON(http.StatusCreated, scheduled)
+}
+
+// GET /rooms/:id/scheduled
+// Lists the caller's pending messages, or all of them with ?all=true.
+func ListScheduledHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	messages, err := mongodb.FindScheduledMessages(c.Param("id"), username, c.QueryParam("all") == "true")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "예약 메시지 조회에 실패했습니다"})
+	}
+	return c.JSON(http.StatusOK, messages)
+}
+
+// PATCH /rooms/:id/scheduled/:scheduledId
+func UpdateScheduledHandler(c echo.Context) error {
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+	if _, err := requireScheduleRoom(c, username); err != nil {
+		return err
+	}
+
+	var req UpdateScheduledRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 요청입니다"})
+	}
+	var update mongodb.ScheduledMessageUpdate
+	if req.Message != nil {
+		if msg := validateScheduledMessage(*req.Message); msg != "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+		}
+		escaped := html.EscapeString(*req.Message)
+		update.Message = &escaped
+	}
+	if req.SendAt != nil {
+		if msg := validateSendAt(*req.SendAt, time.Now()); msg != "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+		}
+		sendAt := req.SendAt.UTC()
+		update.SendAt = &sendAt
+	}
+
+	scheduled, err := mongodb.UpdateScheduledMessage(c.Param("scheduledId"), c.Param("id"), username, update)
+	if err != nil {
+		if errors.Is(err, mongodb.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "예약 메시지를 찾을 수 없습니다"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "예약 메시지 수정에 실패했습니다"})
+	}
+	return c.JSON(http.StatusOK, scheduled)
+}
+
+// DELETE /rooms/:id/scheduled/:scheduledId
+func CancelScheduledHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	username := GetUsername(c)
+	if username == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
+	}
+
+	if err := mongodb.CancelScheduledMessage(c.Param("scheduledId"), c.Param("id"), username); err != nil {
+		if errors.Is(err, mongodb.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "예약 메시지를 찾을 수 없습니다"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "예약 취소에 실패했습니다"})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "예약이 취소되었습니다"})
+}
+
+// StartScheduler posts scheduled messages as they fall due, polling every
+// scheduledPollInterval until StopScheduler is called. Each message is
+// claimed in MongoDB before it is posted, so every replica may run the
+// scheduler and a message is still posted exactly once.
+func StartScheduler() {
+	scheduledStop = make(chan struct{})
+	scheduledWg.Add(1)
+	go func() {
+		defer scheduledWg.Done()
+		ticker := time.NewTicker(scheduledPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				postDueScheduled(time.Now())
+			case <-scheduledStop:
+				return
+			}
+		}
+	}()
+}
+
+// StopScheduler stops the scheduler and waits for a running poll to finish.
+func StopScheduler() {
+	if scheduledStop == nil {
+		return
+	}
+	close(scheduledStop)
+	scheduledWg.Wait()
+}
+
+// postDueScheduled claims and posts the messages due at now.
+func postDueScheduled(now time.Time) {
+	for i := 0; i < scheduledPollBatch; i++ {
+		msg, err := mongodb.ClaimDueScheduledMessage(now, now.Add(-scheduledClaimTimeout))
+		if err != nil {
+			logger.Logger.Errorw("ClaimDueScheduledMessage failed", "error", err)
+			return
+		}
+		if msg == nil {
+			return
+		}
+		postScheduled(*msg)
+	}
+}
+
+// postScheduled posts a claimed message to its room like a live MSG, or
+// records why it was skipped. The message is stored under the scheduled
+// message's ID before it is marked sent, so a server taking over a stale
+// claim finds it and never posts it twice. On a transient failure the claim
+// is left to go stale so the message is retried.
+func postScheduled(sm mongodb.ScheduledMessage) {
+	id := sm.ID.Hex()
+
+	// A taken-over claim may already have been posted before its server died.
+	// The lookup ignores expiry, as the message may have expired since.
+	posted, err := scheduledChatExists(id)
+	if err != nil {
+		logger.Logger.Warnw("scheduler: message lookup failed", "scheduled_id", id, "error", err)
+		return
+	}
+	if posted {
+		finishScheduled(sm, mongodb.ScheduledSent, "")
+		return
+	}
+
+	room, err := scheduledFindRoom(sm.RoomID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			finishScheduled(sm, mongodb.ScheduledSkipped, skipRoomGone)
+		}
+		return
+	}
+	if reason := scheduledSkipReason(*room, sm.Username); reason != "" {
+		finishScheduled(sm, mongodb.ScheduledSkipped, reason)
+		return
+	}
+	if activeMute(sm.RoomID, sm.Username) != nil {
+		finishScheduled(sm, mongodb.ScheduledSkipped, skipMuted)
+		return
+	}
+
+	seq, err := scheduledNextSeq(sm.RoomID)
+	if err != nil {
+		logger.Logger.Warnw("scheduler: NextRoomSeq failed", "room_id", sm.RoomID, "scheduled_id", id, "error", err)
+		return
+	}
+	now := time.Now()
+	msg := mongodb.ChatMessage{
+		Event:     "MSG",
+		User:      sm.Username,
+		Message:   sm.Message,
+		RoomID:    sm.RoomID,
+		MessageID: id,
+		CreatedAt: now.Format("2006-01-02T15:04:05Z07:00"),
+		Seq:       seq,
+		Mentions:  parseMentions(sm.Message),
+		ExpiresAt: expiresAt(now, messageTTL(room, 0)),
+	}
+	stored := msg
+	stored.Event = ""
+	if _, err := scheduledInsertChat(stored); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// Another server posted it after our lookup.
+			finishScheduled(sm, mongodb.ScheduledSent, "")
+			return
+		}
+		logger.Logger.Warnw("scheduler: insert failed, will retry", "room_id", sm.RoomID, "scheduled_id", id, "error", err)
+		return
+	}
+	finishScheduled(sm, mongodb.ScheduledSent, "")
+
+	RoomMgr.Broadcast(sm.RoomID, msg)
+	if msg.Mentions != nil {
+		go notifyMentions(msg)
+	}
+}
+
+// finishScheduled records the outcome of a claimed scheduled message.
+func finishScheduled(sm mongodb.ScheduledMessage, status, reason string) {
+	if err := scheduledFinishMessage(sm.ID, status, reason); err != nil {
+		logger.Logger.Errorw("FinishScheduledMessage failed", "scheduled_id", sm.ID.Hex(), "error", err)
+		return
+	}
+	if status == mongodb.ScheduledSkipped {
+		logger.AuditLog("scheduled_message_skipped", sm.Username, zap.String("room_id", sm.RoomID), zap.String("reason", reason))
+	}
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestValidateSendAt(t *testing.T) {
+	now := time.Now()
+	assert.Empty(t, validateSendAt(now.Add(time.Minute), now))
+	assert.Empty(t, validateSendAt(now.Add(maxScheduleAhead), now))
+	assert.NotEmpty(t, validateSendAt(now, now))
+	assert.NotEmpty(t, validateSendAt(now.Add(-time.Minute), now))
+	assert.NotEmpty(t, validateSendAt(now.Add(maxScheduleAhead+time.Second), now))
+}
+
+// TestScheduledSkipReason verifies a due message is skipped once its author
+// has left the room or may no longer post there.
+func TestScheduledSkipReason(t *testing.T) {
+	room := roleTestRoom()
+	assert.Empty(t, scheduledSkipReason(room, "mary"))
+	assert.Equal(t, skipNotMember, scheduledSkipReason(room, "nobody"))
+
+	room.Archived = true
+	assert.Equal(t, skipArchived, scheduledSkipReason(room, "mary"))
+
+	room = roleTestRoom()
+	room.ReadOnly = true
+	assert.Equal(t, skipReadOnly, scheduledSkipReason(room, "mary"))
+	assert.Empty(t, scheduledSkipReason(room, "mike"))
+
+	assert.Empty(t, scheduledSkipReason(mongodb.Room{IsDefault: true}, "nobody"))
+}
+
+// fakeScheduledStore stands in for MongoDB behind postScheduled.
+type fakeScheduledStore struct {
+	room     *mongodb.Room
+	chats    map[string]mongodb.ChatMessage
+	inserts  int
+	seq      int64
+	statuses []string
+	// hideNext makes the next existence check miss, as when another server
+	// inserts between the check and the insert.
+	hideNext bool
+}
+
+// useFakeScheduledStore routes postScheduled to a fake store and a local hub
+// for room, restoring both when the test ends.
+func useFakeScheduledStore(t *testing.T, room *mongodb.Room) (*fakeScheduledStore, *Hub) {
+	store := &fakeScheduledStore{room: room, chats: make(map[string]mongodb.ChatMessage)}
+	exists, find, next, insert, finish := scheduledChatExists, scheduledFindRoom, scheduledNextSeq, scheduledInsertChat, scheduledFinishMessage
+	scheduledChatExists = func(id string) (bool, error) {
+		if store.hideNext {
+			store.hideNext = false
+			return false, nil
+		}
+		_, ok := store.chats[id]
+		return ok, nil
+	}
+	scheduledFindRoom = func(string) (*mongodb.Room, error) { return store.room, nil }
+	scheduledNextSeq = func(string) (int64, error) {
+		store.seq++
+		return store.seq, nil
+	}
+	scheduledInsertChat = func(msg mongodb.ChatMessage) (string, error) {
+		if _, ok := store.chats[msg.MessageID]; ok {
+			return "", mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+		}
+		store.inserts++
+		store.chats[msg.MessageID] = msg
+		return msg.MessageID, nil
+	}
+	scheduledFinishMessage = func(_ primitive.ObjectID, status, _ string) error {
+		store.statuses = append(store.statuses, status)
+		return nil
+	}
+
+	roomID := room.ID.Hex()
+	hub := &Hub{RoomID: roomID, Broadcast: make(chan mongodb.ChatMessage, 8), stop: make(chan struct{})}
+	RoomMgr.mu.Lock()
+	RoomMgr.hubs[roomID] = hub
+	RoomMgr.mu.Unlock()
+
+	t.Cleanup(func() {
+		scheduledChatExists, scheduledFindRoom, scheduledNextSeq, scheduledInsertChat, scheduledFinishMessage = exists, find, next, insert, finish
+		RoomMgr.mu.Lock()
+		delete(RoomMgr.hubs, roomID)
+		RoomMgr.mu.Unlock()
+	})
+	return store, hub
+}
+
+// TestPostScheduled_TakeoverPostsOnce verifies a claimed message is stored
+// under its scheduled ID and broadcast once, and that a server taking over
+// the claim afterwards only marks it sent.
+func TestPostScheduled_TakeoverPostsOnce(t *testing.T) {
+	room := &mongodb.Room{ID: primitive.NewObjectID(), Members: []string{"alice"}}
+	store, hub := useFakeScheduledStore(t, room)
+	sm := mongodb.ScheduledMessage{
+		ID:       primitive.NewObjectID(),
+		RoomID:   room.ID.Hex(),
+		Username: "alice",
+		Message:  "good morning",
+		Status:   mongodb.ScheduledSending,
+	}
+
+	postScheduled(sm)
+	if assert.Len(t, hub.Broadcast, 1) {
+		msg := <-hub.Broadcast
+		assert.Equal(t, "MSG", msg.Event)
+		assert.Equal(t, sm.ID.Hex(), msg.MessageID)
+		assert.Equal(t, int64(1), msg.Seq)
+	}
+	assert.Equal(t, []string{mongodb.ScheduledSent}, store.statuses)
+
+	// The claim went stale before it was marked sent and is taken over.
+	postScheduled(sm)
+	assert.Equal(t, 1, store.inserts, "a taken-over message must not be stored twice")
+	assert.Empty(t, hub.Broadcast, "a taken-over message must not be broadcast twice")
+	assert.Equal(t, int64(1), store.seq, "a taken-over message must not use another seq")
+	assert.Equal(t, []string{mongodb.ScheduledSent, mongodb.ScheduledSent}, store.statuses)
+}
+
+// TestPostScheduled_TakeoverOfExpiredMessage verifies a message already
+// posted and since expired still counts as posted.
+func TestPostScheduled_TakeoverOfExpiredMessage(t *testing.T) {
+	room := &mongodb.Room{ID: primitive.NewObjectID(), Members: []string{"alice"}}
+	store, hub := useFakeScheduledStore(t, room)
+	sm := mongodb.ScheduledMessage{ID: primitive.NewObjectID(), RoomID: room.ID.Hex(), Username: "alice", Message: "secret"}
+	store.chats[sm.ID.Hex()] = mongodb.ChatMessage{
+		MessageID: sm.ID.Hex(),
+		ExpiresAt: time.Now().Add(-time.Minute).Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	postScheduled(sm)
+	assert.Zero(t, store.inserts)
+	assert.Empty(t, hub.Broadcast)
+	assert.Equal(t, []string{mongodb.ScheduledSent}, store.statuses)
+}
+
+// TestPostScheduled_ConcurrentInsert verifies that losing the insert to
+// another server marks the message sent without broadcasting it again.
+func TestPostScheduled_ConcurrentInsert(t *testing.T) {
+	room := &mongodb.Room{ID: primitive.NewObjectID(), Members: []string{"alice"}}
+	store, hub := useFakeScheduledStore(t, room)
+	sm := mongodb.ScheduledMessage{ID: primitive.NewObjectID(), RoomID: room.ID.Hex(), Username: "alice", Message: "hi"}
+	store.chats[sm.ID.Hex()] = mongodb.ChatMessage{MessageID: sm.ID.Hex()}
+	store.hideNext = true
+
+	postScheduled(sm)
+	assert.Zero(t, store.inserts)
+	assert.Empty(t, hub.Broadcast)
+	assert.Equal(t, []string{mongodb.ScheduledSent}, store.statuses)
+}
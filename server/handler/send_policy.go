@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/woonglife62/woongkie-talkie/pkg/logger"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	redisclient "github.com/woonglife62/woongkie-talkie/pkg/redis"
+)
+
+const (
+	// maxSlowModeSeconds caps a room's slow mode interval.
+	maxSlowModeSeconds = 6 * 60 * 60
+	// roomCheckInterval bounds how long a socket trusts its cached room settings.
+	roomCheckInterval = 5 * time.Second
+)
+
+// warnPayload is the Payload of a WARN event that tells the client how long
+// to wait before sending again.
+type warnPayload struct {
+	RetryAfter int `json:"retry_after"` // seconds
+}
+
+// slowModeKey is the claim key of a user's slow mode cooldown in a room.
+func slowModeKey(roomID, username string) string {
+	return "slow:" + roomID + ":" + username
+}
+
+// claimRemaining returns how long a claim taken by claimOnce still holds.
+func claimRemaining(key string) time.Duration {
+	if redisclient.IsAvailable() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ttl, err := redisclient.ClaimTTL(ctx, key)
+		if err == nil {
+			return ttl
+		}
+		logger.Logger.Warnw("redis claim ttl failed, using local store", "key", key, "error", err)
+	}
+	return localClaims.remaining(key)
+}
+
+// slowModeWait starts username's slow mode cooldown in room and returns 0,
+// or returns how long they must still wait. Callers skip it for users who may
+// bypass slow mode.
+func slowModeWait(room mongodb.Room, username string) time.Duration {
+	if room.SlowModeSeconds <= 0 {
+		return 0
+	}
+	key := slowModeKey(room.ID.Hex(), username)
+	if claimOnce(key, time.Duration(room.SlowModeSeconds)*time.Second) {
+		return 0
+	}
+	if wait := claimRemaining(key); wait > 0 {
+		return wait
+	}
+	// The cooldown ended between the two calls.
+	return time.Second
+}
+
+// slowModeWarning is the error shown to a user still in their cooldown.
+func slowModeWarning(wait time.Duration) string {
+	return fmt.Sprintf("슬로우 모드가 적용된 채팅방입니다. %s 후에 다시 보낼 수 있습니다.", formatRemaining(wait))
+}
+
+// retryAfterSeconds rounds wait up to whole seconds.
+func retryAfterSeconds(wait time.Duration) int {
+	return int((wait + time.Second - 1) / time.Second)
+}
+
+const (
+	// readOnlyWarning is the error shown to a user who may not post in a read-only room.
+	readOnlyWarning = "읽기 전용 채널입니다. 허용된 사용자만 메시지를 보낼 수 있습니다."
+	// archivedWarning is the error shown to anyone sending to an archived room.
+	archivedWarning = "보관된 채팅방에는 메시지를 보낼 수 없습니다."
+)
+
+// requireCanSend rejects a REST send by username to the ":id" room when the
+// room is archived or read-only for them or they are muted. It returns the
+// room otherwise. Slow mode is claimed separately, by claimSlowMode, once the
+// request is known to be valid.
+func requireCanSend(c echo.Context, roomID, username string) (*mongodb.Room, error) {
+	room, err := mongodb.FindRoomByID(roomID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "채팅방을 찾을 수 없습니다")
+	}
+	if room.Archived {
+		return nil, echo.NewHTTPError(http.StatusForbidden, archivedWarning)
+	}
+	if !roomCanPost(*room, username) {
+		return nil, echo.NewHTTPError(http.StatusForbidden, readOnlyWarning)
+	}
+	if err := requireNotMuted(roomID, username); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// slowModeApplies reports whether room's slow mode holds username back.
+func slowModeApplies(room mongodb.Room, username string) bool {
+	return room.SlowModeSeconds > 0 && !roomCan(room, username, roomActionBypassSlowMode)
+}
+
+// claimSlowMode starts username's slow mode cooldown for a REST send, or
+// rejects it with 429 while they are still in their cooldown. Callers claim
+// it last, just before persisting, and releaseSlowMode it if that fails.
+func claimSlowMode(c echo.Context, room mongodb.Room, username string) error {
+	if !slowModeApplies(room, username) {
+		return nil
+	}
+	if wait := slowModeWait(room, username); wait > 0 {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(wait)))
+		return echo.NewHTTPError(http.StatusTooManyRequests, slowModeWarning(wait))
+	}
+	return nil
+}
+
+// releaseSlowMode gives back a cooldown taken by claimSlowMode for a send
+// that was not delivered.
+func releaseSlowMode(room mongodb.Room, username string) {
+	if slowModeApplies(room, username) {
+		releaseClaim(slowModeKey(room.ID.Hex(), username))
+	}
+}
+
+// findClientRoom loads a socket's room; tests replace it to run readPump
+// without MongoDB.
+var findClientRoom = mongodb.FindRoomByID
+
+// currentRoom returns the client's room, re-reading it at most every
+// roomCheckInterval so settings changed at runtime reach open sockets. Only
+// readPump calls it, so the cache needs no lock.
+func (c *Client) currentRoom() *mongodb.Room {
+	now := time.Now()
+	if c.room == nil || now.Sub(c.roomCheckedAt) >= roomCheckInterval {
+		if room, err := findClientRoom(c.RoomID); err == nil {
+			c.setRoom(room)
+		}
+		c.roomCheckedAt = now
+	}
+	return c.room
+}
+
+// refreshRoom re-reads the client's room now, for checks that must not use a
+// stale copy, and returns nil if it cannot be read.
+func (c *Client) refreshRoom() *mongodb.Room {
+	room, err := findClientRoom(c.RoomID)
+	if err != nil {
+		return nil
+	}
+	c.setRoom(room)
+	c.roomCheckedAt = time.Now()
+	return room
+}
+
+// setRoom caches room and the send rules derived from it.
+func (c *Client) setRoom(room *mongodb.Room) {
+	c.room = room
+	c.postDenied = !roomCanPost(*room, c.Username)
+	c.slowModeExempt = room.SlowModeSeconds > 0 && roomCan(*room, c.Username, roomActionBypassSlowMode)
+}
+
+// allowSend checks a message from this socket against the room's send rules
+// and, when it may not be sent, tells the client why with a WARN. Slow mode
+// is left to claimSlowMode.
+func (c *Client) allowSend() bool {
+	room := c.currentRoom()
+	if room != nil && room.Archived {
+		c.sendWarn(archivedWarning)
+		return false
+	}
+	if room != nil && c.postDenied {
+		c.sendWarn(readOnlyWarning)
+		return false
+	}
+	if mute := c.currentMute(); mute != nil {
+		c.sendWarn(muteWarning(mute, time.Now()))
+		return false
+	}
+	return true
+}
+
+// claimSlowMode starts this socket's slow mode cooldown, or tells the client
+// how long to wait with a WARN. Call it after allowSend, once the message is
+// valid, and releaseSlowMode if it is then not delivered.
+func (c *Client) claimSlowMode() bool {
+	room := c.currentRoom()
+	if room == nil || c.slowModeExempt {
+		return true
+	}
+	if wait := slowModeWait(*room, c.Username); wait > 0 {
+		c.sendRetryWarn(slowModeWarning(wait), wait)
+		return false
+	}
+	return true
+}
+
+// releaseSlowMode gives back a cooldown taken by claimSlowMode.
+func (c *Client) releaseSlowMode() {
+	if c.room != nil && c.room.SlowModeSeconds > 0 && !c.slowModeExempt {
+		releaseClaim(slowModeKey(c.RoomID, c.Username))
+	}
+}
+
+// sendRetryWarn queues a WARN whose payload carries the remaining wait.
+func (c *Client) sendRetryWarn(text string, wait time.Duration) {
+	payload, _ := json.Marshal(warnPayload{RetryAfter: retryAfterSeconds(wait)})
+	c.trySend(mongodb.ChatMessage{
+		User:    "system",
+		Message: text,
+		RoomID:  c.RoomID,
+		Event:   "WARN",
+		Payload: payload,
+	})
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestSlowModeWait verifies the first send starts the cooldown and the next
+// one reports the time left, using the local claim store.
+func TestSlowModeWait(t *testing.T) {
+	room := mongodb.Room{ID: primitive.NewObjectID(), SlowModeSeconds: 30}
+
+	assert.Zero(t, slowModeWait(room, "alice"))
+	wait := slowModeWait(room, "alice")
+	assert.Greater(t, wait, 25*time.Second)
+	assert.LessOrEqual(t, wait, 30*time.Second)
+
+	// Cooldowns are per user.
+	assert.Zero(t, slowModeWait(room, "bob"))
+
+	room.SlowModeSeconds = 0
+	assert.Zero(t, slowModeWait(room, "alice"))
+}
+
+// TestClient_ClaimSlowMode verifies a socket in cooldown gets a WARN
+// carrying the remaining wait, and exempt users are never held back.
+func TestClient_ClaimSlowMode(t *testing.T) {
+	room := &mongodb.Room{ID: primitive.NewObjectID(), SlowModeSeconds: 60}
+	c := &Client{
+		Send:          make(chan mongodb.ChatMessage, 4),
+		Username:      "alice",
+		RoomID:        room.ID.Hex(),
+		room:          room,
+		roomCheckedAt: time.Now(),
+		muteCheckedAt: time.Now(),
+	}
+
+	assert.True(t, c.allowSend(), "allowSend must not claim the cooldown")
+	assert.True(t, c.allowSend())
+	assert.True(t, c.claimSlowMode())
+	assert.False(t, c.claimSlowMode())
+	warn := <-c.Send
+	assert.Equal(t, "WARN", warn.Event)
+	assert.Contains(t, warn.Message, "슬로우 모드")
+	var payload warnPayload
+	if assert.NoError(t, json.Unmarshal(warn.Payload, &payload)) {
+		assert.Equal(t, 60, payload.RetryAfter)
+	}
+
+	c.slowModeExempt = true
+	assert.True(t, c.claimSlowMode())
+}
+
+// TestClient_ReleaseSlowMode verifies a send that was not delivered gives its
+// cooldown back.
+func TestClient_ReleaseSlowMode(t *testing.T) {
+	room := &mongodb.Room{ID: primitive.NewObjectID(), SlowModeSeconds: 60}
+	c := &Client{
+		Send:          make(chan mongodb.ChatMessage, 4),
+		Username:      "alice",
+		RoomID:        room.ID.Hex(),
+		room:          room,
+		roomCheckedAt: time.Now(),
+	}
+
+	assert.True(t, c.claimSlowMode())
+	c.releaseSlowMode()
+	assert.True(t, c.claimSlowMode(), "a released cooldown must not hold the next send")
+	assert.False(t, c.claimSlowMode())
+}
+
+func TestClaimStore_Remaining(t *testing.T) {
+	s := &claimStore{expires: make(map[string]time.Time)}
+	assert.Zero(t, s.remaining("k"))
+	s.claim("k", time.Minute)
+	assert.Greater(t, s.remaining("k"), 55*time.Second)
+	s.release("k")
+	assert.Zero(t, s.remaining("k"))
+}
+
+// TestRoomCanPost verifies read-only rooms accept moderators and the
+// allow-list only.
+func TestRoomCanPost(t *testing.T) {
+	room := roleTestRoom()
+	assert.True(t, roomCanPost(room, "mary"))
+
+	room.ReadOnly = true
+	room.Posters = []string{"mary"}
+	assert.True(t, roomCanPost(room, "olivia"))
+	assert.True(t, roomCanPost(room, "mike"))
+	assert.True(t, roomCanPost(room, "mary"))
+	assert.False(t, roomCanPost(room, "max"))
+}
+
+func TestClient_AllowSend_ReadOnly(t *testing.T) {
+	room := &mongodb.Room{ID: primitive.NewObjectID(), ReadOnly: true}
+	c := &Client{
+		Send:          make(chan mongodb.ChatMessage, 1),
+		Username:      "alice",
+		RoomID:        room.ID.Hex(),
+		room:          room,
+		roomCheckedAt: time.Now(),
+		postDenied:    true,
+	}
+
+	assert.False(t, c.allowSend())
+	warn := <-c.Send
+	assert.Equal(t, "WARN", warn.Event)
+	assert.Equal(t, readOnlyWarning, warn.Message)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+type ThreadResponse struct {
+	Parent  mongodb.Chat   `json:"parent"`
+	Replies []mongodb.Chat `json:"replies"`
+	HasMore bool           `json:"has_more"`
+}
+
+// threadParentError checks that parent can head a thread in roomID. Threads
+// are one level deep, so a reply cannot have replies of its own. Returns the
+// HTTP status and user-facing message, or 0 and "" when it can.
+func threadParentError(parent *mongodb.Chat, roomID string) (int, string) {
+	if parent == nil || parent.RoomID != roomID {
+		return http.StatusNotFound, "원본 메시지를 찾을 수 없습니다"
+	}
+	if parent.ReplyTo != "" {
+		return http.StatusBadRequest, "답장에는 스레드를 만들 수 없습니다"
+	}
+	return 0, ""
+}
+
+// broadcastThreadUpdate sends the parent's current reply summary to the room so
+// clients can render "N replies" without refetching the thread.
+func broadcastThreadUpdate(roomID string, parent *mongodb.Chat) {
+	msg := mongodb.ChatMessage{
+		Event:      "THREAD_UPDATE",
+		User:       parent.User,
+		RoomID:     roomID,
+		MessageID:  parent.ID.Hex(),
+		ReplyCount: parent.ReplyCount,
+	}
+	if parent.LastReplyAt != nil {
+		msg.LastReplyAt = parent.LastReplyAt.Format(time.RFC3339)
+	}
+	RoomMgr.Broadcast(roomID, msg)
+}
+
+// GET /rooms/:id/messages/:msgId/thread?before=RFC3339&limit=50
+func GetThreadHandler(c echo.Context) error {
+	if err := requireRoomMember(c); err != nil {
+		return err
+	}
+	roomID := c.Param("id")
+	msgID := c.Param("msgId")
+
+	parent, err := mongodb.FindChatByID(msgID)
+	if err != nil {
+		parent = nil
+	}
+	if status, msg := threadParentError(parent, roomID); status != 0 {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+
+	limit := int64(50)
+	if l, err := strconv.ParseInt(c.QueryParam("limit"), 10, 64); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	var before time.Time
+	if beforeStr := c.QueryParam("before"); beforeStr != "" {
+		before, err = time.Parse(time.RFC3339, beforeStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "잘못된 시간 형식입니다"})
+		}
+	}
+
+	replies, err := mongodb.FindThreadReplies(roomID, msgID, before, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "스레드 조회에 실패했습니다"})
+	}
+
+	return c.JSON(http.StatusOK, ThreadResponse{
+		Parent:  *parent,
+		Replies: replies,
+		HasMore: int64(len(replies)) == limit,
+	})
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+// TestGetThreadHandler_InvalidMessageID verifies that a malformed parent ID
+// returns 404 before any database access.
+func TestGetThreadHandler_InvalidMessageID(t *testing.T) {
+	e := newTestEcho()
+
+	req := httptest.NewRequest(http.MethodGet, "/rooms//messages/not-an-id/thread", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "msgId")
+	c.SetParamValues("", "not-an-id")
+	c.Set("username", "alice")
+
+	err := GetThreadHandler(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// TestThreadParentError verifies that only a top-level message in the same
+// room can head a thread.
+func TestThreadParentError(t *testing.T) {
+	status, msg := threadParentError(nil, "r1")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.NotEmpty(t, msg)
+
+	status, _ = threadParentError(&mongodb.Chat{RoomID: "r2"}, "r1")
+	assert.Equal(t, http.StatusNotFound, status, "a parent from another room must not be found")
+
+	status, msg = threadParentError(&mongodb.Chat{RoomID: "r1", ReplyTo: "p1"}, "r1")
+	assert.Equal(t, http.StatusBadRequest, status, "a reply to a reply must be rejected")
+	assert.NotEmpty(t, msg)
+
+	status, msg = threadParentError(&mongodb.Chat{RoomID: "r1"}, "r1")
+	assert.Zero(t, status)
+	assert.Empty(t, msg)
+}
+
+// TestRoomAccessError_NonMember verifies that threads of a private room are
+// closed to non-members while public rooms stay open.
+func TestRoomAccessError_NonMember(t *testing.T) {
+	private := mongodb.Room{Members: []string{"alice"}}
+
+	err := roomAccessError(private, "mallory")
+	if assert.Error(t, err) {
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	}
+	err = roomAccessError(private, "")
+	if assert.Error(t, err) {
+		assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+	}
+	assert.NoError(t, roomAccessError(private, "alice"))
+	assert.NoError(t, roomAccessError(mongodb.Room{IsPublic: true}, "mallory"))
+}
+
+// TestThreadUpdate_Whitelisted verifies THREAD_UPDATE crosses instances but
+// cannot be sent by clients.
+func TestThreadUpdate_Whitelisted(t *testing.T) {
+	assert.True(t, isAllowedEvent("THREAD_UPDATE"))
+	assert.False(t, clientEvents["THREAD_UPDATE"])
+}
@@ -98,6 +98,11 @@ func UploadFileHandler(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "인증이 필요합니다"})
 	}
 
+	room, err := requireCanSend(c, roomID, username)
+	if err != nil {
+		return err
+	}
+
 	// #238/#272: SSRF – resolve hostname and reject private/loopback IPs
 	host := c.Request().Host
 	if host != "" {
@@ -200,6 +205,12 @@ func UploadFileHandler(c echo.Context) error {
 	}
 	dst.Close()
 
+	// The upload is valid and on disk; only now does it count against slow mode.
+	if err := claimSlowMode(c, *room, username); err != nil {
+		os.Remove(savePath)
+		return err
+	}
+
 	fileID := primitive.NewObjectID()
 	meta := mongodb.FileMetadata{
 		ID:       fileID,
@@ -216,6 +227,7 @@ func UploadFileHandler(c echo.Context) error {
 	saved, err := mongodb.InsertFileMetadata(meta)
 	if err != nil {
 		os.Remove(savePath)
+		releaseSlowMode(*room, username)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "메타데이터 저장에 실패했습니다"})
 	}
 
@@ -15,6 +15,7 @@ import (
 	redisclient "github.com/woonglife62/woongkie-talkie/pkg/redis"
 	"github.com/woonglife62/woongkie-talkie/server/middleware"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/time/rate"
 )
 
@@ -30,6 +31,9 @@ const (
 
 	insertBatchSize    = 50
 	insertBatchTimeout = 100 * time.Millisecond
+
+	insertMaxAttempts  = 3
+	insertRetryBackoff = 200 * time.Millisecond
 )
 
 var upgrader = websocket.Upgrader{
@@ -40,8 +44,15 @@ var upgrader = websocket.Upgrader{
 	EnableCompression: true,
 }
 
+// insertJob is a message waiting to be persisted together with the client
+// that sent it, so the sender can be told whether the write succeeded.
+type insertJob struct {
+	msg    mongodb.ChatMessage
+	sender *Client
+}
+
 // insertQueue is a buffered channel for async MongoDB chat inserts.
-var insertQueue = make(chan mongodb.ChatMessage, insertQueueSize)
+var insertQueue = make(chan insertJob, insertQueueSize)
 
 // insertWg tracks live insert worker goroutines for graceful shutdown.
 var insertWg sync.WaitGroup
@@ -67,7 +78,7 @@ func ShutdownInsertQueue() {
 // runInsertWorker batches messages from insertQueue and bulk-inserts them.
 // It flushes when the batch reaches insertBatchSize or insertBatchTimeout elapses.
 func runInsertWorker() {
-	batch := make([]mongodb.ChatMessage, 0, insertBatchSize)
+	batch := make([]insertJob, 0, insertBatchSize)
 	ticker := time.NewTicker(insertBatchTimeout)
 	defer ticker.Stop()
 
@@ -75,23 +86,18 @@ func runInsertWorker() {
 		if len(batch) == 0 {
 			return
 		}
-		if _, err := mongodb.InsertManyChat(batch); err != nil {
-			logger.Logger.Errorw("batch InsertManyChat failed",
-				"count", len(batch),
-				"error", err,
-			)
-		}
+		persistBatch(batch)
 		batch = batch[:0]
 	}
 
 	for {
 		select {
-		case msg, ok := <-insertQueue:
+		case job, ok := <-insertQueue:
 			if !ok {
 				flush()
 				return
 			}
-			batch = append(batch, msg)
+			batch = append(batch, job)
 			if len(batch) >= insertBatchSize {
 				flush()
 			}
@@ -101,6 +107,109 @@ func runInsertWorker() {
 	}
 }
 
+// persistBatch inserts a batch, retrying with exponential backoff. Inserts are
+// idempotent on the broadcast message ID, so the whole batch is retried. A batch
+// that still fails is parked in the dead-letter collection. Each sender gets a
+// MSG_ACK or MSG_FAILED for its message.
+func persistBatch(batch []insertJob) {
+	messages := make([]mongodb.ChatMessage, len(batch))
+	for i, job := range batch {
+		messages[i] = job.msg
+	}
+
+	var err error
+	for attempt := 1; attempt <= insertMaxAttempts; attempt++ {
+		if _, err = mongodb.InsertManyChat(messages); err == nil {
+			ackBatch(batch, "MSG_ACK")
+			return
+		}
+		logger.Logger.Warnw("batch InsertManyChat failed",
+			"count", len(batch),
+			"attempt", attempt,
+			"error", err,
+		)
+		if attempt < insertMaxAttempts {
+			time.Sleep(insertRetryBackoff << (attempt - 1))
+		}
+	}
+
+	logger.Logger.Errorw("batch InsertManyChat gave up, moving to dead letters",
+		"count", len(batch),
+		"error", err,
+	)
+	if dlErr := mongodb.InsertDeadLetters(messages, err, insertMaxAttempts); dlErr != nil {
+		logger.Logger.Errorw("InsertDeadLetters failed",
+			"count", len(batch),
+			"error", dlErr,
+		)
+	}
+	metrics.MessagesDeadLettered.Add(float64(len(batch)))
+	ackBatch(batch, "MSG_FAILED")
+}
+
+// enqueueInsert queues msg for persistence; sender, if any, is acked once it
+// is written. Returns false if the queue stayed full for insertTimeout.
+func enqueueInsert(msg mongodb.ChatMessage, sender *Client) bool {
+	select {
+	case insertQueue <- insertJob{msg: msg, sender: sender}:
+		metrics.MessagesTotal.Inc()
+		return true
+	case <-time.After(insertTimeout):
+		metrics.MessagesDropped.Inc()
+		return false
+	}
+}
+
+// ackBatch tells each still-connected sender the persistence outcome of its message.
+func ackBatch(batch []insertJob, event string) {
+	for _, job := range batch {
+		if job.sender == nil {
+			continue
+		}
+		job.sender.trySend(deliveryAck(job.msg, event))
+	}
+}
+
+// deliveryAck builds the MSG_ACK/MSG_FAILED event for a queued message.
+func deliveryAck(msg mongodb.ChatMessage, event string) mongodb.ChatMessage {
+	return mongodb.ChatMessage{
+		Event:       event,
+		User:        msg.User,
+		RoomID:      msg.RoomID,
+		MessageID:   msg.MessageID,
+		ClientMsgID: msg.ClientMsgID,
+		Seq:         msg.Seq,
+		CreatedAt:   msg.CreatedAt,
+	}
+}
+
+// reackAttempts bounds how often a resent client_msg_id is looked up while
+// the first send may still be in the insert queue.
+const reackAttempts = 5
+
+// reackDuplicate answers a resent client_msg_id with the MSG_ACK of the
+// message stored for the first send, so a sender whose ack or echo was lost
+// can reconcile. It waits briefly for a first send still being inserted.
+func (c *Client) reackDuplicate(clientMsgID string) {
+	for attempt := 1; attempt <= reackAttempts; attempt++ {
+		chat, err := mongodb.FindChatByClientMsgID(c.RoomID, c.Username, clientMsgID)
+		if err == nil {
+			c.trySend(deliveryAck(chatToMessage(*chat, "MSG", c.Username), "MSG_ACK"))
+			return
+		}
+		if err != mongo.ErrNoDocuments {
+			logger.Logger.Warnw("reackDuplicate: lookup failed",
+				"room_id", c.RoomID,
+				"username", c.Username,
+				"client_msg_id", clientMsgID,
+				"error", err,
+			)
+			return
+		}
+		time.Sleep(2 * insertBatchTimeout)
+	}
+}
+
 // Client represents a connected WebSocket client
 type Client struct {
 	Hub       *Hub
@@ -110,11 +219,99 @@ type Client struct {
 	RoomID    string
 	msgLimit  *rate.Limiter
 	closeOnce sync.Once
+	sendMu    sync.Mutex
+	closed    bool           // Send has been closed; guarded by sendMu
+	closeMsg  string         // close frame reason set by closeWithReason; guarded by sendMu
+	replayed  map[int64]bool // seqs written by a resume replay whose live copies are due
+	replayTop int64          // highest seq in replayed
+
+	readMu      sync.Mutex  // guards the READ coalescing state below
+	lastRead    string      // last message ID acknowledged via READ, to skip repeats
+	pendingRead string      // latest READ waiting for the next marker write
+	readTimer   *time.Timer // pending marker write, nil when none is scheduled
+	readAt      time.Time   // time of the last marker write
+
+	mute          *mongodb.RoomSanction // cached active mute, see currentMute
+	muteCheckedAt time.Time
+
+	room           *mongodb.Room // cached room settings, see currentRoom
+	roomCheckedAt  time.Time
+	postDenied     bool // the room is read-only for the user, see roomCanPost
+	slowModeExempt bool // the user may bypass the room's slow mode
 }
 
 // closeSend closes the Send channel exactly once, preventing double-close panics.
 func (c *Client) closeSend() {
-	c.closeOnce.Do(func() { close(c.Send) })
+	c.closeOnce.Do(func() {
+		c.sendMu.Lock()
+		c.closed = true
+		close(c.Send)
+		c.sendMu.Unlock()
+	})
+}
+
+// closeWithReason closes the connection with a policy-violation close frame
+// carrying reason, so the client knows it was removed rather than dropped.
+func (c *Client) closeWithReason(reason string) {
+	c.sendMu.Lock()
+	if !c.closed {
+		c.closeMsg = reason
+	}
+	c.sendMu.Unlock()
+	c.closeSend()
+}
+
+// trySend queues msg for this client from outside the hub goroutine. It drops
+// the message if the Send buffer is full or the client has already gone.
+func (c *Client) trySend(msg mongodb.ChatMessage) bool {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if c.closed {
+		return false
+	}
+	select {
+	case c.Send <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeDirect writes msg straight to the connection. Only safe while no
+// writePump is running for this client.
+func (c *Client) writeDirect(msg mongodb.ChatMessage) error {
+	c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.Conn.WriteJSON(msg)
+}
+
+// setReplayed records the seqs a resume replay wrote that will also arrive
+// live. Must be called before writePump starts.
+func (c *Client) setReplayed(replayed map[int64]bool) {
+	if len(replayed) == 0 {
+		return
+	}
+	c.replayed = replayed
+	for seq := range replayed {
+		if seq > c.replayTop {
+			c.replayTop = seq
+		}
+	}
+}
+
+// skipReplayed reports whether msg is the live copy of a message the resume
+// replay already delivered. Only MSG is filtered; other events that carry a
+// seq, such as MSG_EXPIRE and MSG_ACK, always go through. The set is dropped
+// once the live stream passes the last replayed seq. Only writePump calls it.
+func (c *Client) skipReplayed(msg mongodb.ChatMessage) bool {
+	if c.replayed == nil || msg.Event != "MSG" || msg.Seq <= 0 {
+		return false
+	}
+	dup := c.replayed[msg.Seq]
+	delete(c.replayed, msg.Seq)
+	if msg.Seq >= c.replayTop || len(c.replayed) == 0 {
+		c.replayed = nil
+	}
+	return dup
 }
 
 // writePump pumps messages from the Send channel to the WebSocket connection.
@@ -130,10 +327,19 @@ func (c *Client) writePump() {
 			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
 				// Hub closed the channel — send a clean close frame.
-				closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "connection closed")
+				code, reason := websocket.CloseNormalClosure, "connection closed"
+				c.sendMu.Lock()
+				if c.closeMsg != "" {
+					code, reason = websocket.ClosePolicyViolation, c.closeMsg
+				}
+				c.sendMu.Unlock()
+				closeMsg := websocket.FormatCloseMessage(code, reason)
 				_ = c.Conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(writeWait))
 				return
 			}
+			if c.skipReplayed(msg) {
+				continue
+			}
 			if err := c.Conn.WriteJSON(msg); err != nil {
 				logger.Logger.Warnw("writePump WriteJSON error",
 					"username", c.Username,
@@ -158,6 +364,71 @@ func (c *Client) writePump() {
 	}
 }
 
+// clientEvents is the whitelist of event types a client may send over the socket.
+var clientEvents = map[string]bool{
+	"MSG":             true,
+	"MSG_FILE":        true,
+	"TYPING_START":    true,
+	"TYPING_STOP":     true,
+	"REACTION_ADD":    true,
+	"REACTION_REMOVE": true,
+	"READ":            true,
+}
+
+// sendWarn queues a system WARN event for this client only, dropping it if
+// the Send buffer is full.
+func (c *Client) sendWarn(text string) {
+	c.trySend(mongodb.ChatMessage{
+		User:    "system",
+		Message: text,
+		RoomID:  c.RoomID,
+		Event:   "WARN",
+	})
+}
+
+// readAckInterval is the least time between two read marker writes of one
+// socket. READ acks arriving faster are coalesced into the latest one.
+const readAckInterval = time.Second
+
+// queueRead schedules a read marker write for msgID, at most one per
+// readAckInterval, so a client flooding READ cannot flood MongoDB.
+func (c *Client) queueRead(msgID string) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	if msgID == c.lastRead {
+		return
+	}
+	c.pendingRead = msgID
+	if c.readTimer != nil {
+		return
+	}
+	wait := readAckInterval - time.Since(c.readAt)
+	if wait < 0 {
+		wait = 0
+	}
+	c.readTimer = time.AfterFunc(wait, c.flushRead)
+}
+
+// flushRead writes the latest queued READ.
+func (c *Client) flushRead() {
+	c.readMu.Lock()
+	msgID := c.pendingRead
+	c.pendingRead, c.readTimer, c.readAt = "", nil, time.Now()
+	c.readMu.Unlock()
+
+	if err := markRead(c.RoomID, c.Username, msgID); err != nil {
+		logger.Logger.Warnw("readPump: markRead failed",
+			"room_id", c.RoomID,
+			"username", c.Username,
+			"error", err,
+		)
+		return
+	}
+	c.readMu.Lock()
+	c.lastRead = msgID
+	c.readMu.Unlock()
+}
+
 // hubSendTimeout is the maximum time readPump will wait to deliver a message
 // to the hub's Broadcast or Unregister channels. If the hub has already
 // exited, the send would block forever without this guard.
@@ -223,11 +494,14 @@ func (c *Client) readPump() {
 		}
 		msg.User = c.Username
 		msg.RoomID = c.RoomID
+		msg.Seq = 0 // server-assigned only
+		msg.ExpiresAt = ""
+		msg.Poll = nil // polls are created via POST /rooms/:id/polls
 
-		// Whitelist: only accept MSG, MSG_FILE and TYPING_* events from clients.
+		// Whitelist: only accept events listed in clientEvents.
 		// Reject any other event type (e.g. OPEN, CLOSE, WARN) to prevent
 		// clients from spoofing server-generated events (#102, #257).
-		if msg.Event != "MSG" && msg.Event != "MSG_FILE" && msg.Event != "TYPING_START" && msg.Event != "TYPING_STOP" {
+		if !clientEvents[msg.Event] {
 			logger.Logger.Warnw("readPump: rejected disallowed event type",
 				"username", c.Username,
 				"event", msg.Event,
@@ -245,6 +519,27 @@ func (c *Client) readPump() {
 			continue
 		}
 
+		// Reaction events are persisted directly and fanned out with live counts.
+		if msg.Event == "REACTION_ADD" || msg.Event == "REACTION_REMOVE" {
+			if !c.msgLimit.Allow() {
+				c.sendWarn("메시지 전송이 너무 빠릅니다. 잠시 후 다시 시도해주세요.")
+				continue
+			}
+			if _, err := applyReaction(c.RoomID, msg.MessageID, c.Username, msg.Emoji, msg.Event == "REACTION_ADD"); err != nil {
+				c.sendWarn("리액션을 처리할 수 없습니다.")
+			}
+			continue
+		}
+
+		// Read acknowledgements move the read marker; the READ receipt itself is
+		// broadcast by markRead so stale or repeated acks are never fanned out.
+		if msg.Event == "READ" {
+			if msg.MessageID != "" {
+				c.queueRead(msg.MessageID)
+			}
+			continue
+		}
+
 		// Validate: reject empty messages (#262)
 		if strings.TrimSpace(msg.Message) == "" {
 			continue
@@ -252,36 +547,81 @@ func (c *Client) readPump() {
 
 		// Rate limit: 30 msg/min per client
 		if !c.msgLimit.Allow() {
-			warn := mongodb.ChatMessage{
-				User:    "system",
-				Message: "메시지 전송이 너무 빠릅니다. 잠시 후 다시 시도해주세요.",
-				RoomID:  c.RoomID,
-				Event:   "WARN",
-			}
-			select {
-			case c.Send <- warn:
-			default:
-			}
+			c.sendWarn("메시지 전송이 너무 빠릅니다. 잠시 후 다시 시도해주세요.")
 			continue
 		}
 
 		// Validate message length (max 2000 chars)
 		if len([]rune(msg.Message)) > 2000 {
-			warn := mongodb.ChatMessage{
-				User:    "system",
-				Message: "메시지는 2000자를 초과할 수 없습니다.",
-				RoomID:  c.RoomID,
-				Event:   "WARN",
+			c.sendWarn("메시지는 2000자를 초과할 수 없습니다.")
+			continue
+		}
+
+		if !validExpiresIn(msg.ExpiresIn) {
+			c.sendWarn("자동 삭제 시간은 최대 7일까지 지정할 수 있습니다.")
+			continue
+		}
+
+		// Idempotent sends: a resent client_msg_id within the dedup window is
+		// not sent again; the sender gets the first send's MSG_ACK instead.
+		// The ID is claimed before a slash command runs, so a resent command
+		// does not run twice either.
+		if msg.ClientMsgID != "" && !clientMsgIDPattern.MatchString(msg.ClientMsgID) {
+			c.sendWarn("잘못된 메시지 ID입니다.")
+			continue
+		}
+		name, args, isCommand := "", "", false
+		if msg.Event == "MSG" && !msg.Encrypted {
+			name, args, isCommand = parseCommand(msg.Message)
+		}
+		if !claimClientMsgID(c.RoomID, c.Username, msg.ClientMsgID) {
+			logger.Logger.Debugw("readPump: duplicate client_msg_id dropped",
+				"room_id", c.RoomID,
+				"username", c.Username,
+				"client_msg_id", msg.ClientMsgID,
+			)
+			// A command's answer went to the caller with the first send.
+			if !isCommand {
+				go c.reackDuplicate(msg.ClientMsgID)
 			}
-			select {
-			case c.Send <- warn:
-			default:
+			continue
+		}
+
+		// Slash commands run here, before anything is persisted. A command
+		// with a public response continues as a message with that text; "//"
+		// sends a message starting with one slash. A command that did not run
+		// gives its client_msg_id back so it can be retried.
+		if isCommand {
+			text, ran := c.runCommand(name, args)
+			if !ran {
+				releaseClientMsgID(c.RoomID, c.Username, msg.ClientMsgID)
+			}
+			if strings.TrimSpace(text) == "" {
+				continue
 			}
+			msg.Message = text
+		} else if msg.Event == "MSG" && !msg.Encrypted && strings.HasPrefix(msg.Message, "//") {
+			msg.Message = msg.Message[1:]
+		}
+
+		// Room send rules: archived rooms, read-only channels and mutes. Slow
+		// mode is claimed only once the message is accepted, below.
+		if !c.allowSend() {
+			releaseClientMsgID(c.RoomID, c.Username, msg.ClientMsgID)
 			continue
 		}
 
 		// Sanitize message content
 		msg.Message = html.EscapeString(msg.Message)
+		msg.Mentions = nil
+		if msg.Event == "MSG" && !msg.Encrypted {
+			msg.Mentions = parseMentions(msg.Message)
+		}
+
+		if !c.claimSlowMode() {
+			releaseClientMsgID(c.RoomID, c.Username, msg.ClientMsgID)
+			continue
+		}
 
 		// Generate stable message ID and timestamp for the broadcast so clients
 		// receive consistent metadata immediately (before the async DB insert).
@@ -289,40 +629,57 @@ func (c *Client) readPump() {
 		msg.CreatedAt = time.Now().Format("2006-01-02T15:04:05Z07:00")
 
 		if msg.Event == "MSG" {
+			// Allocate the room sequence number before broadcasting so every
+			// recipient sees the same seq that is persisted.
+			seq, err := mongodb.NextRoomSeq(c.RoomID)
+			if err != nil {
+				logger.Logger.Warnw("readPump: NextRoomSeq failed",
+					"room_id", c.RoomID,
+					"username", c.Username,
+					"error", err,
+				)
+				releaseClientMsgID(c.RoomID, c.Username, msg.ClientMsgID)
+				c.releaseSlowMode()
+				c.sendWarn("메시지 저장에 실패했습니다. 잠시 후 다시 시도해주세요.")
+				continue
+			}
+			msg.Seq = seq
+			msg.ExpiresAt = expiresAt(time.Now(), messageTTL(c.currentRoom(), msg.ExpiresIn))
+			// Persist exactly the ID, timestamp and expiry being broadcast.
 			chatMessage := mongodb.ChatMessage{
-				User:    msg.User,
-				Message: msg.Message,
-				RoomID:  c.RoomID,
+				User:        msg.User,
+				Message:     msg.Message,
+				RoomID:      c.RoomID,
+				MessageID:   msg.MessageID,
+				CreatedAt:   msg.CreatedAt,
+				Seq:         seq,
+				ClientMsgID: msg.ClientMsgID,
+				Mentions:    msg.Mentions,
+				ExpiresAt:   msg.ExpiresAt,
 			}
-			select {
-			case insertQueue <- chatMessage:
-				metrics.MessagesTotal.Inc()
-			case <-time.After(insertTimeout):
+			if !enqueueInsert(chatMessage, c) {
 				logger.Logger.Warnw("insertQueue full after timeout, dropping message",
 					"room_id", c.RoomID,
 					"username", c.Username,
 				)
-				metrics.MessagesDropped.Inc()
+				releaseClientMsgID(c.RoomID, c.Username, msg.ClientMsgID)
+				c.releaseSlowMode()
 				// Notify client that message was dropped (#260)
-				warn := mongodb.ChatMessage{
-					User:    "system",
-					Message: "메시지 저장에 실패했습니다. 잠시 후 다시 시도해주세요.",
-					RoomID:  c.RoomID,
-					Event:   "WARN",
-				}
-				select {
-				case c.Send <- warn:
-				default:
-				}
+				c.trySend(deliveryAck(chatMessage, "MSG_FAILED"))
+				c.sendWarn("메시지 저장에 실패했습니다. 잠시 후 다시 시도해주세요.")
 				continue
 			}
 		}
 
+		msg.ExpiresIn = 0
 		select {
 		case c.Hub.Broadcast <- msg:
 		case <-c.Hub.stop:
 			return
 		}
+		if msg.Mentions != nil {
+			go notifyMentions(msg)
+		}
 	}
 }
 
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+)
+
+// TestClient_TrySendAfterClose verifies that sending to a client whose Send
+// channel was closed is dropped instead of panicking.
+func TestClient_TrySendAfterClose(t *testing.T) {
+	c := &Client{Send: make(chan mongodb.ChatMessage, 1)}
+
+	assert.True(t, c.trySend(mongodb.ChatMessage{Event: "MSG_ACK"}))
+	assert.False(t, c.trySend(mongodb.ChatMessage{Event: "MSG_ACK"}), "full buffer drops")
+
+	c.closeSend()
+	assert.NotPanics(t, func() {
+		assert.False(t, c.trySend(mongodb.ChatMessage{Event: "MSG_ACK"}))
+	})
+}
+
+// TestDeliveryAck verifies that acks carry the IDs the sender needs to reconcile.
+func TestDeliveryAck(t *testing.T) {
+	msg := mongodb.ChatMessage{
+		Event:       "MSG",
+		User:        "alice",
+		Message:     "hello",
+		RoomID:      "room-1",
+		MessageID:   "65f000000000000000000001",
+		CreatedAt:   "2024-01-02T03:04:05Z",
+		Seq:         7,
+		ClientMsgID: "c-1",
+	}
+
+	ack := deliveryAck(msg, "MSG_ACK")
+	assert.Equal(t, "MSG_ACK", ack.Event)
+	assert.Equal(t, msg.MessageID, ack.MessageID)
+	assert.Equal(t, msg.ClientMsgID, ack.ClientMsgID)
+	assert.Equal(t, msg.Seq, ack.Seq)
+	assert.Empty(t, ack.Message, "acks must not repeat the message body")
+}
+
+// TestClient_SkipReplayed verifies that only live MSG copies of replayed seqs
+// are dropped and that the set is released once the live stream passes it.
+func TestClient_SkipReplayed(t *testing.T) {
+	c := &Client{}
+	c.setReplayed(map[int64]bool{11: true, 12: true})
+	assert.Equal(t, int64(12), c.replayTop)
+
+	assert.False(t, c.skipReplayed(mongodb.ChatMessage{Event: "MSG_EXPIRE", Seq: 11}), "MSG_EXPIRE must not be filtered")
+	assert.False(t, c.skipReplayed(mongodb.ChatMessage{Event: "MSG_ACK", Seq: 11}), "MSG_ACK must not be filtered")
+	assert.True(t, c.skipReplayed(mongodb.ChatMessage{Event: "MSG", Seq: 11}))
+	assert.False(t, c.skipReplayed(mongodb.ChatMessage{Event: "MSG", Seq: 11}), "a seq is dropped once")
+	assert.NotNil(t, c.replayed)
+
+	assert.True(t, c.skipReplayed(mongodb.ChatMessage{Event: "MSG", Seq: 12}))
+	assert.Nil(t, c.replayed, "the set is released at the last replayed seq")
+	assert.False(t, c.skipReplayed(mongodb.ChatMessage{Event: "MSG", Seq: 13}))
+}
+
+// TestClient_SkipReplayedReleasedPastTop verifies that seqs never seen live do
+// not keep the set alive once newer messages arrive.
+func TestClient_SkipReplayedReleasedPastTop(t *testing.T) {
+	c := &Client{}
+	c.setReplayed(map[int64]bool{20: true, 21: true})
+
+	assert.False(t, c.skipReplayed(mongodb.ChatMessage{Event: "MSG", Seq: 22}))
+	assert.Nil(t, c.replayed)
+}
@@ -1,18 +1,172 @@
 package handler
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/labstack/echo/v4"
 	"github.com/woonglife62/woongkie-talkie/pkg/logger"
 	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
 )
 
-// RoomWebSocket handles WebSocket connections for a specific room
+const (
+	// replayPageSize is the number of messages fetched per page on resume.
+	replayPageSize = 200
+	// replayCatchUpAttempts bounds how often resume waits for messages that
+	// were allocated a seq but are still in the async insert queue.
+	replayCatchUpAttempts = 3
+)
+
+// chatToMessage converts a stored Chat into a wire message of the given event
+// for username, marking it as owned when username sent it.
+func chatToMessage(chat mongodb.Chat, event, username string) mongodb.ChatMessage {
+	msg := mongodb.ChatMessage{
+		User:        chat.User,
+		Message:     chat.Message,
+		RoomID:      chat.RoomID,
+		Event:       event,
+		MessageID:   chat.ID.Hex(),
+		ReplyTo:     chat.ReplyTo,
+		CreatedAt:   chat.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Reactions:   chat.Reactions,
+		Seq:         chat.Seq,
+		ClientMsgID: chat.ClientMsgID,
+		Revision:    chat.Revision,
+		Mentions:    chat.Mentions,
+		Poll:        chat.Poll,
+		Owner:       chat.User == username,
+	}
+	if chat.ExpiresAt != nil {
+		msg.ExpiresAt = chat.ExpiresAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if chat.ReplyCount > 0 && chat.LastReplyAt != nil {
+		msg.ReplyCount = chat.ReplyCount
+		msg.LastReplyAt = chat.LastReplyAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return msg
+}
+
+// queueHistory puts the latest room history into the client's Send buffer as
+// CHATLOG events. Limit to last 256 messages to avoid overflowing the Send buffer (#138).
+func queueHistory(client *Client) {
+	chatList, err := mongodb.FindChatByRoom(client.RoomID)
+	if err != nil {
+		return
+	}
+	if len(chatList) > 256 {
+		chatList = chatList[len(chatList)-256:]
+	}
+	for _, pastChat := range chatList {
+		// Non-blocking send: drop history if buffer is full (#265)
+		select {
+		case client.Send <- chatToMessage(pastChat, "CHATLOG", client.Username):
+		default:
+		}
+	}
+}
+
+// writeGap tells a resuming client, before its replay, that messages after
+// since have already been purged.
+func writeGap(client *Client, since int64) error {
+	oldest, err := mongodb.OldestRoomSeq(client.RoomID)
+	if err != nil {
+		return err
+	}
+	if oldest <= since+1 {
+		return nil
+	}
+	return client.writeDirect(mongodb.ChatMessage{
+		Event:   "GAP",
+		User:    "system",
+		RoomID:  client.RoomID,
+		Message: strconv.FormatInt(since, 10),
+		Seq:     oldest,
+	})
+}
+
+// replaySince writes every message with seq > since directly to the client's
+// connection as CHATLOG events, page by page, and returns the last seq it
+// passed. It must run before writePump starts (so it is the only writer).
+// When replayed is non-nil every replayed seq is added to it: once the client
+// is registered any of them may still arrive live, even one allocated before
+// registering, and writePump drops those copies.
+func replaySince(client *Client, since int64, replayed map[int64]bool) (int64, error) {
+	// Messages allocated up to head may still be queued for insert; wait
+	// briefly for them rather than skipping past them.
+	head, err := mongodb.CurrentRoomSeq(client.RoomID)
+	if err != nil {
+		return since, err
+	}
+
+	last := since
+	for attempt := 0; ; {
+		chats, err := mongodb.FindChatByRoomSinceSeq(client.RoomID, last, replayPageSize)
+		if err != nil {
+			return last, err
+		}
+		for _, chat := range chats {
+			last = chat.Seq
+			if chat.IsDeleted {
+				continue
+			}
+			if err := client.writeDirect(chatToMessage(chat, "CHATLOG", client.Username)); err != nil {
+				return last, err
+			}
+			if replayed != nil {
+				replayed[chat.Seq] = true
+			}
+		}
+		if len(chats) == replayPageSize {
+			continue
+		}
+		if last >= head || attempt >= replayCatchUpAttempts {
+			return last, nil
+		}
+		attempt++
+		time.Sleep(2 * insertBatchTimeout)
+	}
+}
+
+// resume replays every message after since to a client whose writePump has
+// not started, and registers it with hub. The backlog is replayed before
+// registering, so a long replay cannot fill Send and get the client evicted;
+// only what arrived meanwhile is replayed once registered. The client is
+// registered even when the replay fails.
+func resume(hub *Hub, client *Client, since int64) error {
+	if err := writeGap(client, since); err != nil {
+		hub.Register <- client
+		return err
+	}
+	last, err := replaySince(client, since, nil)
+	hub.Register <- client
+	if err != nil {
+		return err
+	}
+
+	replayed := make(map[int64]bool)
+	_, err = replaySince(client, last, replayed)
+	client.setReplayed(replayed)
+	return err
+}
+
+// RoomWebSocket handles WebSocket connections for a specific room.
+// With ?since_seq=N the client resumes after seq N: every missed message is
+// replayed before live delivery starts, instead of the latest history.
 func RoomWebSocket(c echo.Context) error {
 	if err := requireRoomMember(c); err != nil {
 		return err
 	}
 	roomID := c.Param("id")
 
+	sinceSeq := int64(-1)
+	if s := c.QueryParam("since_seq"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 0 {
+			return echo.NewHTTPError(400, "잘못된 since_seq 값입니다")
+		}
+		sinceSeq = n
+	}
+
 	ws, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
 	if err != nil {
 		return err
@@ -27,34 +181,30 @@ func RoomWebSocket(c echo.Context) error {
 		"username", clientNm,
 	)
 
-	// Queue history into the Send buffer before registering with the hub.
-	// This guarantees history messages are ordered before any live broadcasts.
-	// Limit to last 256 messages to avoid overflowing the Send buffer (#138).
-	chatList, err := mongodb.FindChatByRoom(roomID)
-	if err == nil {
-		if len(chatList) > 256 {
-			chatList = chatList[len(chatList)-256:]
-		}
-		for _, pastChat := range chatList {
-			tmpMsg := mongodb.ChatMessage{
-				User:      pastChat.User,
-				Message:   pastChat.Message,
-				RoomID:    roomID,
-				Event:     "CHATLOG",
-				MessageID: pastChat.ID.Hex(),
-				CreatedAt: pastChat.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			}
-			if pastChat.User == clientNm {
-				tmpMsg.Owner = true
-			}
-			// Non-blocking send: drop history if buffer is full (#265)
-			select {
-			case client.Send <- tmpMsg:
-			default:
-			}
+	if sinceSeq >= 0 {
+		// Live messages sent once the client is registered wait in the Send
+		// buffer until writePump starts.
+		if err := resume(hub, client, sinceSeq); err != nil {
+			logger.Logger.Warnw("resume replay failed",
+				"room_id", roomID,
+				"username", clientNm,
+				"since_seq", sinceSeq,
+				"error", err,
+			)
 		}
+		// Pins go through Send behind any live messages queued during replay.
+		queuePins(client)
+		go client.writePump()
+		client.readPump() // blocking
+		return nil
 	}
 
+	// Queue history into the Send buffer before registering with the hub.
+	// This guarantees history messages are ordered before any live broadcasts.
+	// Pinned messages follow the history so late joiners see them.
+	queueHistory(client)
+	queuePins(client)
+
 	// Start writePump before registering so the channel is being drained
 	// before the hub can send any live messages.
 	go client.writePump()
@@ -92,31 +242,8 @@ func MsgReceiver(c echo.Context) error {
 	)
 
 	// Queue history before registering to avoid race with live broadcasts.
-	// Limit to last 256 messages to avoid overflowing the Send buffer (#138).
-	chatList, err := mongodb.FindChatByRoom(roomID)
-	if err == nil {
-		if len(chatList) > 256 {
-			chatList = chatList[len(chatList)-256:]
-		}
-		for _, pastChat := range chatList {
-			tmpMsg := mongodb.ChatMessage{
-				User:      pastChat.User,
-				Message:   pastChat.Message,
-				RoomID:    roomID,
-				Event:     "CHATLOG",
-				MessageID: pastChat.ID.Hex(),
-				CreatedAt: pastChat.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			}
-			if pastChat.User == clientNm {
-				tmpMsg.Owner = true
-			}
-			// Non-blocking send: drop history if buffer is full (#265)
-			select {
-			case client.Send <- tmpMsg:
-			default:
-			}
-		}
-	}
+	queueHistory(client)
+	queuePins(client)
 
 	go client.writePump()
 	hub.Register <- client
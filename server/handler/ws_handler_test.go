@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	mongodb "github.com/woonglife62/woongkie-talkie/pkg/mongoDB"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestChatToMessage verifies that stored chats keep their seq and owner flag on the wire.
+func TestChatToMessage(t *testing.T) {
+	replyAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	chat := mongodb.Chat{
+		ID:          primitive.NewObjectID(),
+		CreatedAt:   time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC),
+		RoomID:      "room-1",
+		User:        "alice",
+		Message:     "hello",
+		ReplyCount:  2,
+		LastReplyAt: &replyAt,
+		Seq:         42,
+	}
+
+	own := chatToMessage(chat, "CHATLOG", "alice")
+	assert.Equal(t, "CHATLOG", own.Event)
+	assert.Equal(t, int64(42), own.Seq)
+	assert.Equal(t, chat.ID.Hex(), own.MessageID)
+	assert.Equal(t, "2024-01-02T03:00:00Z", own.CreatedAt)
+	assert.Equal(t, 2, own.ReplyCount)
+	assert.True(t, own.Owner)
+
+	other := chatToMessage(chat, "CHATLOG", "bob")
+	assert.False(t, other.Owner)
+}
+
+// TestRoomWebSocket_InvalidSinceSeq verifies that a malformed since_seq is
+// rejected before the connection is upgraded.
+func TestRoomWebSocket_InvalidSinceSeq(t *testing.T) {
+	e := newTestEcho()
+
+	for _, v := range []string{"abc", "-1"} {
+		req := httptest.NewRequest(http.MethodGet, "/rooms//ws?since_seq="+v, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("")
+		c.Set("username", "alice")
+
+		err := RoomWebSocket(c)
+		if assert.Error(t, err, "since_seq=%s", v) {
+			he, ok := err.(*echo.HTTPError)
+			if assert.True(t, ok) {
+				assert.Equal(t, http.StatusBadRequest, he.Code)
+			}
+		}
+	}
+}
@@ -63,21 +63,65 @@ func Router(e *echo.Echo) {
 	e.POST("/rooms", handler.CreateRoomHandler, middleware.RoomCreateRateLimit())
 	e.GET("/rooms", handler.ListRoomsHandler)
 	e.GET("/rooms/:id", handler.GetRoomHandler)
+	e.PATCH("/rooms/:id", handler.UpdateRoomHandler)
 	e.DELETE("/rooms/:id", handler.DeleteRoomHandler)
 	e.POST("/rooms/:id/join", handler.JoinRoomHandler)
 	e.POST("/rooms/:id/leave", handler.LeaveRoomHandler)
+	e.POST("/rooms/:id/read", handler.MarkReadHandler)
+	e.POST("/rooms/:id/transfer", handler.TransferOwnershipHandler)
+	e.POST("/rooms/:id/archive", handler.ArchiveRoomHandler)
+	e.POST("/rooms/:id/unarchive", handler.UnarchiveRoomHandler)
+	e.GET("/rooms/:id/invites", handler.ListInvitesHandler)
+	e.POST("/rooms/:id/invites", handler.CreateInviteHandler)
+	e.DELETE("/rooms/:id/invites/:inviteId", handler.DeleteInviteHandler)
+	e.POST("/invites/:token/accept", handler.AcceptInviteHandler)
+	e.GET("/rooms/:id/join-requests", handler.ListJoinRequestsHandler)
+	e.POST("/rooms/:id/join-requests/:requestId/approve", handler.ApproveJoinRequestHandler)
+	e.POST("/rooms/:id/join-requests/:requestId/reject", handler.RejectJoinRequestHandler)
+	e.POST("/rooms/:id/members/:username/promote", handler.PromoteMemberHandler)
+	e.POST("/rooms/:id/members/:username/demote", handler.DemoteMemberHandler)
+	e.POST("/rooms/:id/members/:username/kick", handler.KickMemberHandler)
+	e.POST("/rooms/:id/members/:username/ban", handler.BanMemberHandler)
+	e.DELETE("/rooms/:id/members/:username/ban", handler.UnbanMemberHandler)
+	e.POST("/rooms/:id/members/:username/mute", handler.MuteMemberHandler)
+	e.DELETE("/rooms/:id/members/:username/mute", handler.UnmuteMemberHandler)
+	e.GET("/rooms/:id/sanctions", handler.ListSanctionsHandler)
 	e.GET("/rooms/:id/ws", handler.RoomWebSocket, middleware.WSConnLimit())
 	e.GET("/rooms/:id/messages", handler.GetRoomMessagesHandler)
 	e.GET("/rooms/:id/messages/search", handler.SearchMessagesHandler)
 	e.PUT("/rooms/:id/messages/:msgId", handler.EditMessageHandler)
 	e.DELETE("/rooms/:id/messages/:msgId", handler.DeleteMessageHandler)
+	e.GET("/rooms/:id/messages/:msgId/revisions", handler.GetRevisionsHandler)
 	e.POST("/rooms/:id/messages/:msgId/reply", handler.ReplyMessageHandler)
+	e.GET("/rooms/:id/messages/:msgId/thread", handler.GetThreadHandler)
+	e.GET("/rooms/:id/messages/:msgId/reactions", handler.GetReactionsHandler)
+	e.POST("/rooms/:id/messages/:msgId/reactions", handler.AddReactionHandler)
+	e.DELETE("/rooms/:id/messages/:msgId/reactions/:emoji", handler.RemoveReactionHandler)
+	e.POST("/rooms/:id/polls", handler.CreatePollHandler)
+	e.GET("/rooms/:id/messages/:msgId/poll", handler.GetPollHandler)
+	e.POST("/rooms/:id/messages/:msgId/votes", handler.VotePollHandler)
+	e.DELETE("/rooms/:id/messages/:msgId/votes/:option", handler.UnvotePollHandler)
+	e.GET("/rooms/:id/pins", handler.GetPinsHandler)
+	e.POST("/rooms/:id/pins/:msgId", handler.PinMessageHandler)
+	e.DELETE("/rooms/:id/pins/:msgId", handler.UnpinMessageHandler)
+	e.GET("/rooms/:id/scheduled", handler.ListScheduledHandler)
+	e.POST("/rooms/:id/scheduled", handler.ScheduleMessageHandler)
+	e.PATCH("/rooms/:id/scheduled/:scheduledId", handler.UpdateScheduledHandler)
+	e.DELETE("/rooms/:id/scheduled/:scheduledId", handler.CancelScheduledHandler)
 	e.POST("/rooms/:id/upload", handler.UploadFileHandler)
+	e.GET("/dm", handler.ListDMsHandler)
+	e.POST("/dm", handler.CreateGroupDMHandler)
+	e.POST("/dm/:username", handler.OpenDMHandler)
 	e.GET("/files/:fileId", handler.ServeFileHandler)
 
 	// 유저 프로필 API
 	e.GET("/users/:username/profile", handler.GetProfileHandler)
 	e.PUT("/users/me/profile", handler.UpdateProfileHandler)
+	e.GET("/users/me/invites", handler.ListMyInvitesHandler)
+	e.GET("/users/me/mentions", handler.GetMentionsHandler)
+	e.POST("/users/me/mentions/read", handler.MarkMentionsReadHandler)
+	e.PATCH("/users/me/rooms/:id", handler.UpdateRoomPrefsHandler)
+	e.PUT("/users/me/rooms/order", handler.SetRoomOrderHandler)
 
 	// E2E 암호화 키 관리
 	e.PUT("/crypto/keys", handler.UploadPublicKeyHandler)
@@ -92,7 +136,10 @@ func Router(e *echo.Echo) {
 	admin.PUT("/users/:username/block", handler.AdminBlockUserHandler)
 	admin.GET("/rooms", handler.AdminRoomsHandler)
 	admin.DELETE("/rooms/:id", handler.AdminDeleteRoomHandler)
+	admin.PUT("/rooms/:id/legal-hold", handler.AdminLegalHoldHandler)
 	admin.POST("/rooms/:id/announce", handler.AdminAnnounceHandler)
+	admin.GET("/dead-letters", handler.AdminDeadLettersHandler)
+	admin.POST("/dead-letters/:id/replay", handler.AdminReplayDeadLetterHandler)
 
 	// SPA fallback: serve frontend/dist/ if it exists
 	if _, err := os.Stat("frontend/dist"); err == nil {